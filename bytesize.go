@@ -0,0 +1,56 @@
+package envarfig
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// byteSizeUnits are the multipliers of the byte size units, decimal (KB) and binary (KiB)
+var byteSizeUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"pb":  1e15,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+	"pib": 1 << 50,
+}
+
+/*
+info: parses a human byte size (e.g. 10MB, 1.5GiB) into the byte count, the units are
+case insensitive and fractional byte counts are rounded down
+
+useage: parseByteSize("1.5GiB")
+
+args:
+  - value: the byte size to parse
+*/
+func parseByteSize(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	// split the number from its unit
+	unitStart := strings.LastIndexFunc(value, func(r rune) bool {
+		return (r >= '0' && r <= '9') || r == '.'
+	}) + 1
+	number, unit := value[:unitStart], strings.ToLower(strings.TrimSpace(value[unitStart:]))
+
+	multiplier, ok := byteSizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q in %q", unit, value)
+	}
+	size, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	bytes := math.Floor(size * multiplier)
+	if bytes < 0 || bytes >= math.MaxInt64 {
+		return 0, fmt.Errorf("size %q out of range", value)
+	}
+	return int64(bytes), nil
+}
@@ -0,0 +1,41 @@
+//go:build unit
+
+package envarfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int64
+		err      string
+	}{
+		{"512", 512, ""},
+		{"512B", 512, ""},
+		{"10MB", 10_000_000, ""},
+		{"10 mb", 10_000_000, ""},
+		{"1.5GiB", 1_610_612_736, ""},
+		{"4KiB", 4096, ""},
+		{"1.5B", 1, ""},
+		{"10XB", 0, `unknown unit "xb" in "10XB"`},
+		{"MB", 0, `invalid size "MB"`},
+		{"-5MB", 0, `size "-5MB" out of range`},
+		{"9000PiB", 0, `size "9000PiB" out of range`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			size, err := parseByteSize(tt.value)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, size)
+		})
+	}
+}
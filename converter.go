@@ -0,0 +1,70 @@
+package envarfig
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var fieldConverters sync.Map // Map to store the registered per field converters
+
+type fieldConverterKey struct {
+	structType reflect.Type
+	fieldName  string
+}
+
+/*
+info: registers a converter used to parse the env var value of a specific field in a specific struct,
+regardless of the field type
+
+useage: RegisterFieldConverter(reflect.TypeOf(Config{}), "Host", func(value string) (any, error) { return strings.ToLower(value), nil })
+
+args:
+  - structType: the type of the struct containing the field
+  - fieldName: the name of the struct field
+  - converter: a function converting the env var value into the field value
+*/
+func RegisterFieldConverter(structType reflect.Type, fieldName string, converter func(string) (any, error)) {
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	fieldConverters.Store(fieldConverterKey{structType, fieldName}, converter)
+}
+
+func lookupFieldConverter(structType reflect.Type, fieldName string) (func(string) (any, error), bool) {
+	converter, ok := fieldConverters.Load(fieldConverterKey{structType, fieldName})
+	if !ok {
+		return nil, false
+	}
+	return converter.(func(string) (any, error)), true
+}
+
+func setFieldConverterValue(fieldValue reflect.Value, tagProp tagProperties, envValue string, converter func(string) (any, error)) error {
+	converted, err := converter(envValue)
+	if err != nil {
+		return fmt.Errorf("failed to convert %s: %w", tagProp.EnvName, err)
+	}
+	value := reflect.ValueOf(converted)
+	if !value.IsValid() {
+		fieldValue.SetZero()
+		return nil
+	}
+	switch {
+	case value.Type().AssignableTo(fieldValue.Type()):
+		fieldValue.Set(value)
+	case value.Type().ConvertibleTo(fieldValue.Type()):
+		fieldValue.Set(value.Convert(fieldValue.Type()))
+	default:
+		return fmt.Errorf("converter for %s returned %s, but field expects %s", tagProp.EnvName, value.Type(), fieldValue.Type())
+	}
+	return nil
+}
+
+// fieldTransform returns the transform of the field set with WithTransform, by field name or by "Struct.Field"
+func (s *settings) fieldTransform(structType reflect.Type, fieldName string) (func(string) string, bool) {
+	if transform, ok := s.Transforms[structType.Name()+"."+fieldName]; ok {
+		return transform, true
+	}
+	transform, ok := s.Transforms[fieldName]
+	return transform, ok
+}
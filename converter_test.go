@@ -0,0 +1,62 @@
+//go:build unit
+
+package envarfig
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldConverter(t *testing.T) {
+	type Config struct {
+		Host string
+		Port int
+	}
+	configType := reflect.TypeOf(Config{})
+	t.Cleanup(func() {
+		fieldConverters.Delete(fieldConverterKey{configType, "Host"})
+	})
+
+	RegisterFieldConverter(reflect.TypeOf(&Config{}), "Host", func(value string) (any, error) {
+		return strings.ToUpper(value), nil
+	})
+
+	t.Run("Lookup registered converter", func(t *testing.T) {
+		_, ok := lookupFieldConverter(configType, "Host")
+		assert.True(t, ok)
+		_, ok = lookupFieldConverter(configType, "Port")
+		assert.False(t, ok)
+	})
+
+	tests := []struct {
+		name        string
+		converter   func(string) (any, error)
+		expected    any
+		expectError string
+	}{
+		{"Assignable value", func(v string) (any, error) { return 8080, nil }, 8080, ""},
+		{"Convertible value", func(v string) (any, error) { return int64(9090), nil }, 9090, ""},
+		{"Nil value", func(v string) (any, error) { return nil, nil }, 0, ""},
+		{"Converter error", func(v string) (any, error) { return nil, errors.New("bad port") }, 0, "failed to convert PORT: bad port"},
+		{"Mismatched value", func(v string) (any, error) { return "8080", nil }, 0, "converter for PORT returned string, but field expects int"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{Port: 1}
+			fieldValue := reflect.ValueOf(&config).Elem().FieldByName("Port")
+			err := setFieldConverterValue(fieldValue, tagProperties{EnvName: "PORT"}, "8080", tt.converter)
+			if tt.expectError != "" {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectError, err.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, config.Port)
+		})
+	}
+}
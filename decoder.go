@@ -0,0 +1,73 @@
+package envarfig
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// supported encodings of the env var values
+const (
+	encodingBase64 = "base64"
+	encodingGzip   = "gzip"
+	encodingHex    = "hex"
+)
+
+/*
+info: parses the encoding stages, the stages are listed in the order they were applied
+e.g. "gzip+base64" is gzipped first and then base64 encoded
+
+args:
+  - encoding: the encoding stages separated by '+'
+*/
+func parseEncodingStages(encoding string) ([]string, error) {
+	stages := strings.Split(encoding, "+")
+	for i, stage := range stages {
+		stage = strings.TrimSpace(stage)
+		if stage != encodingBase64 && stage != encodingGzip && stage != encodingHex {
+			return nil, fmt.Errorf("unsupported encoding %s", stage)
+		}
+		stages[i] = stage
+	}
+	return stages, nil
+}
+
+/*
+info: decodes the env var value, the stages are decoded in the reverse order they were applied
+
+args:
+  - envName: the name of the env var, used in the errors
+  - envValue: the encoded env var value
+  - stages: the encoding stages in the order they were applied
+*/
+func decodeEnvValue(envName string, envValue string, stages []string) ([]byte, error) {
+	decoded := []byte(strings.TrimSpace(envValue))
+	for i := len(stages) - 1; i >= 0; i-- {
+		var err error
+		switch stages[i] {
+		case encodingBase64:
+			decoded, err = base64.StdEncoding.DecodeString(string(decoded))
+		case encodingGzip:
+			decoded, err = gunzip(decoded)
+		case encodingHex:
+			decoded, err = hex.DecodeString(string(decoded))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s at %s stage: %w", envName, stages[i], err)
+		}
+	}
+	return decoded, nil
+}
+
+func gunzip(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
@@ -0,0 +1,60 @@
+//go:build unit
+
+package envarfig
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func gzipBase64(t *testing.T, value string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write([]byte(value))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestDecodeEnvValue(t *testing.T) {
+	t.Run("Parse encoding stages", func(t *testing.T) {
+		stages, err := parseEncodingStages("gzip + base64")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"gzip", "base64"}, stages)
+
+		_, err = parseEncodingStages("gzip+rot13")
+		assert.Error(t, err)
+		assert.Equal(t, "unsupported encoding rot13", err.Error())
+	})
+
+	tests := []struct {
+		name        string
+		envValue    string
+		stages      []string
+		expected    string
+		expectError string
+	}{
+		{"Base64", base64.StdEncoding.EncodeToString([]byte("hello")), []string{"base64"}, "hello", ""},
+		{"Gzip and base64", gzipBase64(t, "large blob"), []string{"gzip", "base64"}, "large blob", ""},
+		{"Invalid base64", "not base64!", []string{"gzip", "base64"}, "", "failed to decode BLOB at base64 stage: illegal base64 data at input byte 3"},
+		{"Invalid gzip", base64.StdEncoding.EncodeToString([]byte("plain")), []string{"gzip", "base64"}, "", "failed to decode BLOB at gzip stage: unexpected EOF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := decodeEnvValue("BLOB", tt.envValue, tt.stages)
+			if tt.expectError != "" {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectError, err.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(decoded))
+		})
+	}
+}
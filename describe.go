@@ -0,0 +1,182 @@
+package envarfig
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldInfo describes how a struct field is loaded, as resolved from its env tag
+type FieldInfo struct {
+	Field               string
+	Type                reflect.Type
+	EnvName             string
+	Aliases             []string
+	Deprecated          []string
+	Group               string
+	DefaultValue        string
+	EnvironmentDefaults map[string]string
+	Delimiter           string
+	ValueDelimiter      string
+	Required            bool
+	RequiredIf          string
+	RequiredIfValue     string
+	Pattern             string
+	Unique              bool
+	SortOrder           string
+	Versioned           bool
+	Encoding            []string
+	Forbidden           []string
+	JSON                bool
+	Base                int
+	ByteSize            bool
+	Percent             bool
+	Layout              string
+	NoTrim              bool
+	Secret              bool
+	Infer               bool
+	Multiline           bool
+	FromFile            bool
+	Unit                time.Duration
+}
+
+/*
+info: describes the fields of the struct as resolved from their env tags, without
+reading the environment (e.g. for documentation generators)
+
+useage: Describe[Config](WithTagName("cfg"))
+
+args:
+  - options: variadic options resolving the env names like LoadEnv (e.g., tag name, name strategy)
+
+returns:
+  - []FieldInfo: the fields in struct order, including the fields of embedded structs
+  - error: an error if any
+*/
+func Describe[T any](options ...option) ([]FieldInfo, error) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Struct {
+		return nil, ErrConfigNotPtrToStruct
+	}
+	return describeStructFields(typ, loadSettings(options...))
+}
+
+func describeStructFields(typ reflect.Type, settings *settings) ([]FieldInfo, error) {
+	var fields []FieldInfo
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		tagValues, hasTag := field.Tag.Lookup(settings.TagName)
+
+		// promote the fields of untagged embedded structs and struct pointers into the parent
+		if tagValues == "" && isEmbeddedStruct(field) {
+			embeddedType := field.Type
+			if embeddedType.Kind() == reflect.Pointer {
+				embeddedType = embeddedType.Elem()
+			}
+			embeddedFields, err := describeStructFields(embeddedType, settings)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, embeddedFields...)
+			continue
+		}
+		if !hasTag {
+			return nil, ErrTagNotFound
+		}
+		if tagValues == "-" {
+			continue
+		}
+		if !field.IsExported() {
+			return nil, fmt.Errorf("cannot set unexported field %s", field.Name)
+		}
+
+		tagProp, err := parseTagAndTagValues(tagValues)
+		if err != nil {
+			return nil, err
+		}
+		if isEmbeddedStruct(field) && !tagProp.JSON {
+			return nil, embeddedStructTagError(field)
+		}
+		if tagProp.EnvName == "" {
+			tagProp.setEnvName(settings.NameStrategy(field.Name))
+		}
+		fields = append(fields, FieldInfo{
+			Field:               field.Name,
+			Type:                field.Type,
+			EnvName:             tagProp.EnvName,
+			Aliases:             tagProp.Aliases,
+			Deprecated:          tagProp.Deprecated,
+			Group:               tagProp.Group,
+			DefaultValue:        tagProp.DefaultValue,
+			EnvironmentDefaults: tagProp.EnvironmentDefaults,
+			Delimiter:           tagProp.Delimiter,
+			ValueDelimiter:      tagProp.ValueDelimiter,
+			Required:            tagProp.Required,
+			RequiredIf:          tagProp.RequiredIf,
+			RequiredIfValue:     tagProp.RequiredIfValue,
+			Pattern:             tagProp.Pattern,
+			Unique:              tagProp.Unique,
+			SortOrder:           tagProp.SortOrder,
+			Versioned:           tagProp.Versioned,
+			Encoding:            tagProp.Encoding,
+			Forbidden:           tagProp.Forbidden,
+			JSON:                tagProp.JSON,
+			Base:                tagProp.Base,
+			ByteSize:            tagProp.ByteSize,
+			Percent:             tagProp.Percent,
+			Layout:              tagProp.Layout,
+			NoTrim:              tagProp.NoTrim,
+			Secret:              tagProp.Secret,
+			Infer:               tagProp.Infer,
+			Multiline:           tagProp.Multiline,
+			FromFile:            tagProp.FromFile,
+			Unit:                tagProp.Unit,
+		})
+	}
+	return fields, nil
+}
+
+/*
+info: generates a .env skeleton of the struct, the fields use their defaults and the
+required fields without a default are marked with a "# required" comment
+
+useage: GenerateEnvTemplate[Config]() returns "# required\nDATABASE_URL=\nLOG_LEVEL=info\n"
+
+args:
+  - options: variadic options resolving the env names like LoadEnv (e.g., tag name, name strategy)
+
+returns:
+  - string: the .env template, or a comment with the error if the struct can not be described
+*/
+func GenerateEnvTemplate[T any](options ...option) string {
+	fields, err := Describe[T](options...)
+	if err != nil {
+		return fmt.Sprintf("# envarfig: %s\n", err)
+	}
+
+	var template strings.Builder
+	for _, field := range fields {
+		// the glob env vars are named by the user, so only their pattern is documented
+		if isGlobEnvName(field.EnvName) {
+			template.WriteString("# " + field.EnvName + "\n")
+			continue
+		}
+		if field.Required && field.DefaultValue == "" {
+			template.WriteString("# required\n")
+		} else if field.RequiredIf != "" && field.DefaultValue == "" {
+			template.WriteString("# required when " + field.RequiredIf + "=" + field.RequiredIfValue + "\n")
+		}
+		template.WriteString(field.EnvName + "=" + quoteEnvTemplateValue(field.DefaultValue) + "\n")
+	}
+	return template.String()
+}
+
+// quoteEnvTemplateValue quotes the values dotenv would not read back as is
+func quoteEnvTemplateValue(value string) string {
+	if strings.ContainsAny(value, " \t\n#\"'\\") {
+		return strconv.Quote(value)
+	}
+	return value
+}
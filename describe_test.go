@@ -0,0 +1,115 @@
+//go:build unit
+
+package envarfig
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribe(t *testing.T) {
+	type CommonConfig struct {
+		Region string `env:"REGION,default=eu"`
+	}
+	type Config struct {
+		CommonConfig
+		DatabaseURL string   `env:",required"`
+		Hosts       []string `env:"HOSTS,delimiter=';',unique"`
+		Ignored     string   `env:"-"`
+	}
+
+	t.Run("Describe fields", func(t *testing.T) {
+		t.Setenv("REGION", "us")
+		fields, err := Describe[Config]()
+		assert.NoError(t, err)
+		assert.Len(t, fields, 3)
+
+		assert.Equal(t, "Region", fields[0].Field)
+		assert.Equal(t, "REGION", fields[0].EnvName)
+		assert.Equal(t, "eu", fields[0].DefaultValue)
+
+		assert.Equal(t, "DatabaseURL", fields[1].Field)
+		assert.Equal(t, "DATABASE_URL", fields[1].EnvName)
+		assert.True(t, fields[1].Required)
+
+		assert.Equal(t, "HOSTS", fields[2].EnvName)
+		assert.Equal(t, reflect.TypeOf([]string{}), fields[2].Type)
+		assert.Equal(t, ";", fields[2].Delimiter)
+		assert.True(t, fields[2].Unique)
+		assert.False(t, fields[2].Required)
+	})
+
+	t.Run("Describe errors", func(t *testing.T) {
+		type NoTagConfig struct {
+			Host string
+		}
+		_, err := Describe[NoTagConfig]()
+		assert.ErrorIs(t, err, ErrTagNotFound)
+
+		_, err = Describe[int]()
+		assert.ErrorIs(t, err, ErrConfigNotPtrToStruct)
+	})
+
+	t.Run("Describe embedded struct pointer", func(t *testing.T) {
+		type PointerConfig struct {
+			*CommonConfig
+			Host string `env:"HOST"`
+		}
+		fields, err := Describe[PointerConfig]()
+		assert.NoError(t, err)
+		assert.Len(t, fields, 2)
+		assert.Equal(t, "REGION", fields[0].EnvName)
+		assert.Equal(t, "HOST", fields[1].EnvName)
+
+		type TaggedConfig struct {
+			CommonConfig `env:"COMMON"`
+		}
+		_, err = Describe[TaggedConfig]()
+		assert.ErrorIs(t, err, ErrEmbeddedStructTag)
+	})
+
+	t.Run("Describe with options", func(t *testing.T) {
+		type TagConfig struct {
+			DatabaseURL string `cfg:",required"`
+			Region      string `cfg:"REGION"`
+		}
+		fields, err := Describe[TagConfig](WithTagName("cfg"), WithNameStrategy(strings.ToLower))
+		assert.NoError(t, err)
+		assert.Len(t, fields, 2)
+		assert.Equal(t, "databaseurl", fields[0].EnvName)
+		assert.Equal(t, "REGION", fields[1].EnvName)
+	})
+}
+
+func TestGenerateEnvTemplate(t *testing.T) {
+	type Config struct {
+		DatabaseURL string          `env:",required"`
+		LogLevel    string          `env:"LOG_LEVEL,default=info,required"`
+		Greeting    string          `env:"GREETING,default='hello world'"`
+		Timeout     int             `env:"TIMEOUT"`
+		CertFile    string          `env:"CERT_FILE,requiredif=TLS_ENABLED=true"`
+		Features    map[string]bool `env:"FEATURE_*"`
+	}
+
+	t.Run("Generate template", func(t *testing.T) {
+		expected := "# required\nDATABASE_URL=\nLOG_LEVEL=info\nGREETING=\"hello world\"\nTIMEOUT=\n# required when TLS_ENABLED=true\nCERT_FILE=\n# FEATURE_*\n"
+		assert.Equal(t, expected, GenerateEnvTemplate[Config]())
+	})
+
+	t.Run("Generate template errors", func(t *testing.T) {
+		type NoTagConfig struct {
+			Host string
+		}
+		assert.Equal(t, "# envarfig: tag not found\n", GenerateEnvTemplate[NoTagConfig]())
+	})
+
+	t.Run("Generate template with options", func(t *testing.T) {
+		type TagConfig struct {
+			DatabaseURL string `cfg:",required"`
+		}
+		assert.Equal(t, "# required\ndatabaseurl=\n", GenerateEnvTemplate[TagConfig](WithTagName("cfg"), WithNameStrategy(strings.ToLower)))
+	})
+}
@@ -1,32 +1,676 @@
 package envarfig
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/fs"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
 	"github.com/joho/godotenv"
 )
 
 var envLoader = godotenv.Load
+var envOverloader = godotenv.Overload
+var envFileReader = godotenv.Read
+
+// defaultEnvFile is the env file godotenv loads when no file path is given
+const defaultEnvFile = ".env"
 
 /*
 info: loads the env file
 
-useage: loadEnvFile(true, "path/to/envfile") or loadEnvFile(true, []string{"path/to/envfile1", "path/to/envfile2"})
+useage: loadEnvFile(true, "path/to/envfile", false) or loadEnvFile(true, []string{"path/to/envfile1", "path/to/envfile2"}, false)
 
 args:
   - useEnvFile: a boolean value to determine if the env file should be used(uses godotenv)
   - filePath: the file path of the env variables or list of paths
+  - overload: a boolean value to determine if the env file values override the existing env vars
 */
-func loadEnvFile(autoLoadEnv bool, filePath []string) error {
+func loadEnvFile(autoLoadEnv bool, filePath []string, overload bool) error {
+	loader := selectEnvLoader(overload)
 	if autoLoadEnv && filePath == nil {
 		// if filePath is nil, load the default env file
 		// this will load the .env file in the current directory
-		return envLoader()
+		return loader()
 	}
 	if autoLoadEnv && filePath != nil {
-		return envLoader(filePath...)
+		return loader(filePath...)
 	}
 	if !autoLoadEnv && filePath != nil {
-		return errAutoLoadFalseFilePath
+		return ErrAutoLoadFalseFilePath
+	}
+	return nil
+
+}
+
+/*
+info: loads the env files of the settings, including the environment specific env files
+
+args:
+  - settings: the settings of the env files to load
+*/
+func loadSettingsEnvFiles(settings *settings) error {
+	envFiles := settings.EnvFiles
+	if settings.AutoLoadEnv {
+		envFiles = environmentEnvFiles(envFiles, settings.Environment)
+		// the files are checked before godotenv reads them whole
+		files := envFiles
+		if files == nil {
+			files = []string{defaultEnvFile}
+		}
+		if err := checkEnvFileSizes(files, settings.MaxEnvFileSize); err != nil {
+			return err
+		}
+	}
+	var err error
+	switch {
+	case settings.EnvFileOptional:
+		err = loadOptionalEnvFile(settings.AutoLoadEnv, envFiles, settings.OverloadEnv)
+	case settings.AutoLoadEnv && settings.Environment != "":
+		err = loadEnvironmentEnvFiles(envFiles, settings.OverloadEnv)
+	default:
+		err = loadEnvFile(settings.AutoLoadEnv, envFiles, settings.OverloadEnv)
+	}
+	if err != nil {
+		return wrapEnvFileError(envFiles, err)
+	}
+	return nil
+}
+
+/*
+info: reads the env files of the settings into the env content instead of loading them into
+the process env, the missing default env file and environment specific files are ignored
+
+useage: readSettingsEnvFiles(settings) with settings.EnvFiles = []string{".env"}
+
+args:
+  - settings: the settings of the env files to read
+*/
+func readSettingsEnvFiles(settings *settings) (map[string]string, error) {
+	if !settings.AutoLoadEnv {
+		return nil, loadEnvFile(false, settings.EnvFiles, settings.OverloadEnv)
+	}
+	files := settings.EnvFiles
+	if files == nil {
+		files = []string{defaultEnvFile}
+	}
+	envContent := make(map[string]string)
+	for _, file := range files {
+		layers := []string{file}
+		if settings.Environment != "" {
+			layers = []string{file + "." + settings.Environment, file}
+		}
+		for i, layer := range layers {
+			if err := checkEnvFileSizes([]string{layer}, settings.MaxEnvFileSize); err != nil {
+				return nil, err
+			}
+			values, err := envFileReader(layer)
+			optional := settings.EnvFileOptional || settings.EnvFiles == nil || i < len(layers)-1
+			if optional && os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return nil, wrapEnvFileError([]string{layer}, err)
+			}
+			// the first file wins like godotenv.Load, the last one with overload like godotenv.Overload
+			for name, value := range values {
+				if _, ok := envContent[name]; !ok || settings.OverloadEnv {
+					envContent[name] = value
+				}
+			}
+		}
+	}
+	return envContent, nil
+}
+
+/*
+info: reads the layered env files in order into the env content, the later files override
+the earlier ones and the optional environment specific "file.<environment>" overrides its base file,
+the env source still overrides all of them unless overload is set
+
+useage: readLayeredEnvFiles(settings) with settings.LayeredFiles = []string{"defaults.env", "local.env"}
+
+args:
+  - settings: the settings of the layered env files to read
+*/
+func readLayeredEnvFiles(settings *settings) (map[string]string, error) {
+	envContent := make(map[string]string)
+	for _, file := range settings.LayeredFiles {
+		layers := []string{file}
+		if settings.Environment != "" {
+			layers = append(layers, file+"."+settings.Environment)
+		}
+		for i, layer := range layers {
+			if err := checkEnvFileSizes([]string{layer}, settings.MaxEnvFileSize); err != nil {
+				return nil, err
+			}
+			values, err := envFileReader(layer)
+			// the environment specific files are optional overlays
+			if (settings.EnvFileOptional || i > 0) && os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return nil, wrapEnvFileError([]string{layer}, err)
+			}
+			for name, value := range values {
+				envContent[name] = value
+			}
+		}
+	}
+	return envContent, nil
+}
+
+/*
+info: loads the env file, ignoring the files that do not exist
+
+useage: loadOptionalEnvFile(true, "path/to/envfile", false) or loadOptionalEnvFile(true, []string{"path/to/envfile1", "path/to/envfile2"}, false)
+
+args:
+  - useEnvFile: a boolean value to determine if the env file should be used(uses godotenv)
+  - filePath: the file path of the env variables or list of paths
+  - overload: a boolean value to determine if the env file values override the existing env vars
+*/
+func loadOptionalEnvFile(autoLoadEnv bool, filePath []string, overload bool) error {
+	if !autoLoadEnv {
+		return loadEnvFile(autoLoadEnv, filePath, overload)
+	}
+	loader := selectEnvLoader(overload)
+	if filePath == nil {
+		return ignoreNotExist(loader())
+	}
+	// load the files one by one so a missing file does not skip the others
+	for _, file := range filePath {
+		if err := ignoreNotExist(loader(file)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+info: loads the pairs of environment specific and base env files of environmentEnvFiles one by one,
+the environment specific files are optional overlays like the ones of the layered files
+
+useage: loadEnvironmentEnvFiles([]string{".env.staging", ".env"}, false)
+
+args:
+  - envFiles: the environment specific file followed by its base file, for each env file
+  - overload: a boolean value to determine if the env file values override the existing env vars
+*/
+func loadEnvironmentEnvFiles(envFiles []string, overload bool) error {
+	loader := selectEnvLoader(overload)
+	for i, file := range envFiles {
+		err := loader(file)
+		if i%2 == 0 {
+			err = ignoreNotExist(err)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selectEnvLoader returns godotenv.Overload when the env file values should
+// override the existing env vars and godotenv.Load otherwise
+func selectEnvLoader(overload bool) func(filenames ...string) error {
+	if overload {
+		return envOverloader
+	}
+	return envLoader
+}
+
+/*
+info: wraps the env file load error, keeping the underlying godotenv error and the offending path
+so missing files, permission errors and parse errors can be told apart
+
+args:
+  - filePath: the file paths of the env variables, nil for the default env file
+  - err: the error returned by the env loader
+*/
+func wrapEnvFileError(filePath []string, err error) error {
+	// the path error already contains the offending path
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return fmt.Errorf("%w: %w", ErrInvalidEnvPathArgs, err)
+	}
+	if filePath == nil {
+		filePath = []string{defaultEnvFile}
+	}
+	return fmt.Errorf("%w: %s: %w", ErrInvalidEnvPathArgs, strings.Join(filePath, ", "), err)
+}
+
+func ignoreNotExist(err error) error {
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+/*
+info: adds the environment specific env files, each "file.<environment>" is placed
+before its base file so its values take precedence
+
+args:
+  - filePath: the file paths of the env variables, nil for the default env file
+  - environment: the active environment, no files are added when empty
+*/
+func environmentEnvFiles(filePath []string, environment string) []string {
+	if environment == "" {
+		return filePath
+	}
+	if filePath == nil {
+		filePath = []string{defaultEnvFile}
+	}
+	envFiles := make([]string, 0, len(filePath)*2)
+	for _, file := range filePath {
+		envFiles = append(envFiles, file+"."+environment, file)
+	}
+	return envFiles
+}
+
+/*
+info: looks up the env var in the env source and the parsed env content by precedence,
+the env source takes precedence unless overload is set
+
+args:
+  - envName: the name of the env var
+*/
+func (s *settings) lookupEnv(envName string) (string, bool, error) {
+	value, _, ok, err := s.lookupEnvSource(envName)
+	return value, ok, err
+}
+
+// lookupEnvSource looks up the env var like lookupEnv, also returning the source of the value
+func (s *settings) lookupEnvSource(envName string) (string, ValueSource, bool, error) {
+	// the environment is ignored when only the defaults are used
+	if s.DefaultsOnly {
+		return "", 0, false, nil
+	}
+	for _, source := range s.precedence() {
+		switch source {
+		case SourceProcessEnv:
+			value, ok, err := s.EnvSource.Lookup(s.ctx, envName)
+			if err != nil {
+				return "", 0, false, fmt.Errorf("failed to lookup %s: %w", envName, err)
+			}
+			if ok {
+				return value, source, true, nil
+			}
+		case SourceEnvFiles:
+			if value, ok := s.envContent[envName]; ok {
+				return value, source, true, nil
+			}
+		}
+	}
+	return "", 0, false, nil
+}
+
+// envNames returns the names of the env vars in the env source and the parsed env content
+func (s *settings) envNames() ([]string, error) {
+	lister, ok := s.EnvSource.(EnvKeyLister)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T does not implement EnvKeyLister", ErrEnvKeysUnsupported, s.EnvSource)
+	}
+	names, err := lister.Keys(s.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the env var names: %w", err)
+	}
+	for name := range s.envContent {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// envReaderContent is the dotenv content of a reader, kept once read so every load parses the whole content
+type envReaderContent struct {
+	mu      sync.Mutex
+	reader  io.Reader
+	content []byte
+	done    bool
+	err     error
+}
+
+/*
+info: returns the content of the reader, reading it on the first use, with a max size only
+one byte past it is read so the oversized content is rejected without reading all of it
+
+args:
+  - maxSize: the max size of the content in bytes, 0 is unlimited
+*/
+func (c *envReaderContent) read(maxSize int64) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.done || c.err != nil || (maxSize > 0 && int64(len(c.content)) > maxSize) {
+		return c.content, c.err
+	}
+	reader := c.reader
+	if maxSize > 0 {
+		reader = io.LimitReader(reader, maxSize+1-int64(len(c.content)))
+	}
+	rest, err := io.ReadAll(reader)
+	c.content = append(c.content, rest...)
+	if err != nil {
+		c.err = fmt.Errorf("failed to read env content: %w", err)
+	}
+	// a content within the max size was read up to its end
+	c.done = maxSize <= 0 || int64(len(c.content)) <= maxSize
+	return c.content, c.err
+}
+
+/*
+info: parses the dotenv content of the reader (uses godotenv)
+
+args:
+  - reader: the reader of the dotenv content
+  - maxSize: the max size of the content in bytes, 0 is unlimited
+*/
+func parseEnvContent(reader io.Reader, maxSize int64) (map[string]string, error) {
+	// read one byte past the limit to tell a content of exactly the limit from a larger one
+	if maxSize > 0 {
+		content, err := io.ReadAll(io.LimitReader(reader, maxSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read env content: %w", err)
+		}
+		if int64(len(content)) > maxSize {
+			return nil, fmt.Errorf("%w: env content exceeds the limit of %d bytes", ErrEnvFileTooLarge, maxSize)
+		}
+		reader = bytes.NewReader(content)
+	}
+	envContent, err := godotenv.Parse(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse env content: %w", err)
+	}
+	return envContent, nil
+}
+
+// envSource is an env var a config field is loaded from
+type envSource struct {
+	name      string
+	versioned bool
+	indexed   bool
+	glob      bool
+}
+
+/*
+info: hashes the options of the load and the current values of the source env vars, looked up
+through the env source and the env content of the load, used to detect the changes invalidating
+a cached config
+
+args:
+  - settings: the settings of the load
+  - envSources: the source env vars of the config
+*/
+func hashEnvSources(settings *settings, envSources []envSource) uint64 {
+	hash := fnv.New64a()
+	settings.hashOptions(hash)
+	for _, source := range envSources {
+		// a failed lookup is hashed as unset, the load then reports the error
+		value, exist, _ := settings.lookupEnv(source.name)
+		if source.versioned {
+			value, _, exist, _ = lookupVersionedEnv(source.name, settings)
+		}
+		// the indexed env vars change with the number of elements
+		if source.indexed {
+			indices, _ := indexedEnvIndices(source.name, settings)
+			value = fmt.Sprint(indices)
+		}
+		// the glob env vars change with the matching names and their values
+		if source.glob {
+			names, _ := globEnvNames(source.name, settings)
+			values := make(map[string]string, len(names))
+			for _, name := range names {
+				values[name], _, _ = settings.lookupEnv(name)
+			}
+			value = fmt.Sprint(values)
+		}
+		// the separators keep "A=1" and "A=" + "1" apart, and unset apart from empty
+		fmt.Fprintf(hash, "%s\x00%t\x00%s\x00", source.name, exist, value)
+	}
+	return hash.Sum64()
+}
+
+/*
+Hash the options of the load changing the loaded values (e.g. the tag name, the environment or the
+defaults), the env source and the env content are left out as their values are hashed through the
+lookups and the funcs are left out as the loads with value changing funcs are not cached
+*/
+func (s *settings) hashOptions(hash io.Writer) {
+	value := reflect.ValueOf(s).Elem()
+	for i := range value.NumField() {
+		field := value.Type().Field(i)
+		if !field.IsExported() || field.Type.Kind() == reflect.Pointer || field.Type.Kind() == reflect.Interface || isFuncType(field.Type) {
+			continue
+		}
+		fmt.Fprintf(hash, "%s\x00%v\x00", field.Name, value.Field(i).Interface())
+	}
+}
+
+// isFuncType reports whether the type is a func or a map of funcs
+func isFuncType(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Func || (typ.Kind() == reflect.Map && typ.Elem().Kind() == reflect.Func)
+}
+
+/*
+Report whether the options set funcs changing the loaded values, the closures are told apart
+by their code only, not by their captured state, so the loads using them can not be cached
+*/
+func (s *settings) hasValueFuncs() bool {
+	return len(s.Transforms) > 0 || reflect.ValueOf(s.NameStrategy).Pointer() != reflect.ValueOf(toScreamingSnakeCase).Pointer()
+}
+
+// envGroup is a group of env vars of which at least one must be set
+type envGroup struct {
+	envNames []string
+	set      int
+}
+
+// trackEnvGroup adds the env var to its group, counting whether it is set
+func (s *settings) trackEnvGroup(group string, envName string, set bool) {
+	if s.envGroups == nil {
+		s.envGroups = make(map[string]*envGroup)
+	}
+	if s.envGroups[group] == nil {
+		s.envGroups[group] = &envGroup{}
+	}
+	s.envGroups[group].envNames = append(s.envGroups[group].envNames, envName)
+	if set {
+		s.envGroups[group].set++
+	}
+}
+
+/*
+info: checks that at least one env var of each group is set, or exactly one
+with the exclusive groups option
+
+args:
+  - settings: the settings with the tracked env var groups
+*/
+func checkEnvGroups(settings *settings) error {
+	groups := make([]string, 0, len(settings.envGroups))
+	for group := range settings.envGroups {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		envGroup := settings.envGroups[group]
+		if envGroup.set == 0 {
+			return fmt.Errorf("at least one of %v must be set", envGroup.envNames)
+		}
+		if settings.ExclusiveGroups && envGroup.set > 1 {
+			return fmt.Errorf("exactly one of %v must be set", envGroup.envNames)
+		}
 	}
 	return nil
+}
+
+// requiredIfCheck is an unset field required only when another env var has the given value
+type requiredIfCheck struct {
+	envName    string
+	otherName  string
+	otherValue string
+	message    string
+}
 
+// trackRequiredIf adds the unset conditionally required field for the checks after all fields are loaded
+func (s *settings) trackRequiredIf(check requiredIfCheck) {
+	s.requiredIfs = append(s.requiredIfs, check)
+}
+
+// trackFieldValue records the resolved value of the field, including its default
+func (s *settings) trackFieldValue(envName string, value string) {
+	if s.fieldValues == nil {
+		s.fieldValues = make(map[string]string)
+	}
+	s.fieldValues[envName] = value
+}
+
+// trackFieldSource records the source of the field value when the sources are requested
+func (s *settings) trackFieldSource(envName string, source ValueSource) {
+	if s.fieldSources != nil {
+		s.fieldSources[envName] = source.String()
+	}
+}
+
+/*
+info: checks the conditionally required fields once all fields are loaded, the condition
+is matched against the resolved value of the other field or else its env var
+
+args:
+  - settings: the settings with the tracked requiredif checks
+*/
+func checkRequiredIfs(settings *settings) error {
+	for _, check := range settings.requiredIfs {
+		otherValue, ok := settings.fieldValues[check.otherName]
+		if !ok {
+			var err error
+			if otherValue, _, err = settings.lookupEnv(check.otherName); err != nil {
+				return err
+			}
+			// the other env var is a source of the config too
+			settings.envSources = append(settings.envSources, envSource{name: check.otherName})
+		}
+		if strings.EqualFold(strings.TrimSpace(otherValue), check.otherValue) {
+			return withFieldMessage(check.message, fmt.Errorf("%s is required when %s=%s", check.envName, check.otherName, check.otherValue))
+		}
+	}
+	return nil
+}
+
+/*
+info: checks that every env var with the unused prefix is used by a field,
+returns an error listing the unused env vars
+
+args:
+  - settings: the settings with the unused prefix and the tracked source env vars
+*/
+func checkUnusedEnvVars(settings *settings) error {
+	if settings.UnusedPrefix == "" || settings.DefaultsOnly {
+		return nil
+	}
+	used := make(map[string]bool, len(settings.envSources)+1)
+	versioned := make(map[string]bool)
+	for _, source := range settings.envSources {
+		used[source.name] = true
+		if source.versioned {
+			versioned[source.name] = true
+		}
+	}
+	used[settings.EnvironmentVar] = true
+
+	names, err := settings.envNames()
+	if err != nil {
+		return err
+	}
+	var unused []string
+	for _, name := range names {
+		if !strings.HasPrefix(name, settings.UnusedPrefix) || used[name] || isVersionOf(name, versioned) {
+			continue
+		}
+		unused = append(unused, name)
+	}
+	if len(unused) == 0 {
+		return nil
+	}
+	sort.Strings(unused)
+	return fmt.Errorf("unused env vars with prefix %s: %s", settings.UnusedPrefix, strings.Join(unused, ", "))
+}
+
+// isVersionOf reports whether the name is a NAME_V<n> version of a versioned env var
+func isVersionOf(name string, versioned map[string]bool) bool {
+	index := strings.LastIndex(name, "_V")
+	if index < 0 || !versioned[name[:index]] {
+		return false
+	}
+	_, err := strconv.Atoi(name[index+2:])
+	return err == nil
+}
+
+/*
+info: collects the env vars of the process env starting with the prefix, keeping their full names
+(e.g. for debugging or feeding a known subset into WithEnvContent)
+
+useage: EnvMapWithPrefix("APP_")
+
+args:
+  - prefix: the prefix of the env var names
+*/
+func EnvMapWithPrefix(prefix string) map[string]string {
+	return envMapWithPrefix(prefix, false)
+}
+
+/*
+info: collects the env vars of the process env starting with the prefix, stripping
+the prefix from their names (e.g. APP_HOST -> HOST)
+
+useage: EnvMapTrimPrefix("APP_")
+
+args:
+  - prefix: the prefix of the env var names
+*/
+func EnvMapTrimPrefix(prefix string) map[string]string {
+	return envMapWithPrefix(prefix, true)
+}
+
+func envMapWithPrefix(prefix string, trimPrefix bool) map[string]string {
+	envMap := make(map[string]string)
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if trimPrefix {
+			name = strings.TrimPrefix(name, prefix)
+		}
+		envMap[name] = value
+	}
+	return envMap
+}
+
+/*
+Check the env files are not larger than the max size before they are loaded, the missing files
+are left to the loaders and a max size of 0 is unlimited
+*/
+func checkEnvFileSizes(files []string, maxSize int64) error {
+	if maxSize <= 0 {
+		return nil
+	}
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		if info.Size() > maxSize {
+			return fmt.Errorf("%w: %s is %d bytes, exceeding the limit of %d bytes", ErrEnvFileTooLarge, file, info.Size(), maxSize)
+		}
+	}
+	return nil
 }
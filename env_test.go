@@ -3,6 +3,8 @@
 package envarfig
 
 import (
+	"errors"
+	"io/fs"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -46,19 +48,19 @@ func TestLoadGoDotEnv(t *testing.T) {
 		{"AutoLoad with default env file", true, nil, false, nil},
 		{"AutoLoad with custom env file", true, []string{"path/to/envfile"}, false, nil},
 		{"No AutoLoad with default env file", false, nil, false, nil},
-		{"No AutoLoad with custom env file", false, []string{"path/to/envfile"}, true, errAutoLoadFalseFilePath},
-		{"Invalid file path", true, []string{"invalid/path/to/envfile"}, true, errInvalidEnvPathArgs},
-		{"Empty file path", true, []string{""}, true, errInvalidEnvPathArgs},
-		{"Invalid file path with no AutoLoad", false, []string{"invalid/path/to/envfile"}, true, errAutoLoadFalseFilePath},
-		{"Empty file path with no AutoLoad", false, []string{""}, true, errAutoLoadFalseFilePath},
-		{"Invalid file path with AutoLoad", true, []string{"invalid/path/to/envfile"}, true, errInvalidEnvPathArgs},
-		{"Empty file path with AutoLoad", true, []string{""}, true, errInvalidEnvPathArgs},
+		{"No AutoLoad with custom env file", false, []string{"path/to/envfile"}, true, ErrAutoLoadFalseFilePath},
+		{"Invalid file path", true, []string{"invalid/path/to/envfile"}, true, ErrInvalidEnvPathArgs},
+		{"Empty file path", true, []string{""}, true, ErrInvalidEnvPathArgs},
+		{"Invalid file path with no AutoLoad", false, []string{"invalid/path/to/envfile"}, true, ErrAutoLoadFalseFilePath},
+		{"Empty file path with no AutoLoad", false, []string{""}, true, ErrAutoLoadFalseFilePath},
+		{"Invalid file path with AutoLoad", true, []string{"invalid/path/to/envfile"}, true, ErrInvalidEnvPathArgs},
+		{"Empty file path with AutoLoad", true, []string{""}, true, ErrInvalidEnvPathArgs},
 		{"multiple file paths", true, []string{"path/to/envfile1", "path/to/envfile2"}, false, nil},
-		{"multiple file paths with no AutoLoad", false, []string{"path/to/envfile1", "path/to/envfile2"}, true, errAutoLoadFalseFilePath},
-		{"multiple file paths with invalid path", true, []string{"path/to/envfile1", "invalid/path/to/envfile"}, true, errInvalidEnvPathArgs},
-		{"multiple file paths with empty path", true, []string{"path/to/envfile1", ""}, true, errInvalidEnvPathArgs},
-		{"multiple file paths with empty path and no AutoLoad", false, []string{"path/to/envfile1", ""}, true, errAutoLoadFalseFilePath},
-		{"multiple file paths with invalid path and no AutoLoad", false, []string{"path/to/envfile1", "invalid/path/to/envfile"}, true, errAutoLoadFalseFilePath},
+		{"multiple file paths with no AutoLoad", false, []string{"path/to/envfile1", "path/to/envfile2"}, true, ErrAutoLoadFalseFilePath},
+		{"multiple file paths with invalid path", true, []string{"path/to/envfile1", "invalid/path/to/envfile"}, true, ErrInvalidEnvPathArgs},
+		{"multiple file paths with empty path", true, []string{"path/to/envfile1", ""}, true, ErrInvalidEnvPathArgs},
+		{"multiple file paths with empty path and no AutoLoad", false, []string{"path/to/envfile1", ""}, true, ErrAutoLoadFalseFilePath},
+		{"multiple file paths with invalid path and no AutoLoad", false, []string{"path/to/envfile1", "invalid/path/to/envfile"}, true, ErrAutoLoadFalseFilePath},
 	}
 
 	for _, tt := range tests {
@@ -69,7 +71,7 @@ func TestLoadGoDotEnv(t *testing.T) {
 			} else {
 				mockGodotenv.On("Load", tt.filePath).Return(tt.err)
 			}
-			err := loadEnvFile(tt.autoLoad, tt.filePath)
+			err := loadEnvFile(tt.autoLoad, tt.filePath, false)
 			if tt.expectError {
 				assert.Error(t, err)
 				assert.Equal(t, tt.err, err)
@@ -81,3 +83,196 @@ func TestLoadGoDotEnv(t *testing.T) {
 	}
 
 }
+
+func TestEnvironmentEnvFiles(t *testing.T) {
+	tests := []struct {
+		name        string
+		filePath    []string
+		environment string
+		expected    []string
+	}{
+		{"No environment with default env file", nil, "", nil},
+		{"No environment with custom env file", []string{"path/to/envfile"}, "", []string{"path/to/envfile"}},
+		{"Environment with default env file", nil, "staging", []string{".env.staging", ".env"}},
+		{"Environment with multiple env files", []string{"a.env", "b.env"}, "prod", []string{"a.env.prod", "a.env", "b.env.prod", "b.env"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, environmentEnvFiles(tt.filePath, tt.environment))
+		})
+	}
+}
+
+func TestLoadOptionalEnvFile(t *testing.T) {
+	originalEnvLoader := envLoader // Store the original envLoader
+	defer func() {
+		envLoader = originalEnvLoader // Restore the original envLoader after the test
+	}()
+	mockGodotenv := new(MockEnv)
+
+	cleanup := func() {
+		mockGodotenv.ExpectedCalls = nil // Reset the expected calls to the mock
+	}
+
+	envLoader = func(filenames ...string) error {
+		return mockGodotenv.Load(filenames...)
+	}
+
+	notExistErr := &fs.PathError{Op: "open", Path: "missing.env", Err: fs.ErrNotExist}
+	permissionErr := &fs.PathError{Op: "open", Path: "locked.env", Err: fs.ErrPermission}
+
+	tests := []struct {
+		name        string
+		autoLoad    bool
+		filePath    []string
+		loadErrs    map[string]error
+		expectError bool
+		err         error
+	}{
+		{"Missing default env file", true, nil, map[string]error{"": notExistErr}, false, nil},
+		{"Missing custom env file", true, []string{"missing.env"}, map[string]error{"missing.env": notExistErr}, false, nil},
+		{"Missing and existing env files", true, []string{"missing.env", "app.env"}, map[string]error{"missing.env": notExistErr, "app.env": nil}, false, nil},
+		{"Other load error", true, []string{"locked.env"}, map[string]error{"locked.env": permissionErr}, true, permissionErr},
+		{"No AutoLoad with custom env file", false, []string{"missing.env"}, nil, true, ErrAutoLoadFalseFilePath},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(cleanup)
+			for file, err := range tt.loadErrs {
+				if file == "" {
+					mockGodotenv.On("Load").Return(err)
+				} else {
+					mockGodotenv.On("Load", []string{file}).Return(err)
+				}
+			}
+			err := loadOptionalEnvFile(tt.autoLoad, tt.filePath, false)
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Equal(t, tt.err, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockGodotenv.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLoadEnvironmentEnvFiles(t *testing.T) {
+	originalEnvLoader := envLoader // Store the original envLoader
+	defer func() {
+		envLoader = originalEnvLoader // Restore the original envLoader after the test
+	}()
+	mockGodotenv := new(MockEnv)
+
+	cleanup := func() {
+		mockGodotenv.ExpectedCalls = nil // Reset the expected calls to the mock
+	}
+
+	envLoader = func(filenames ...string) error {
+		return mockGodotenv.Load(filenames...)
+	}
+
+	overlayErr := &fs.PathError{Op: "open", Path: ".env.staging", Err: fs.ErrNotExist}
+	baseErr := &fs.PathError{Op: "open", Path: ".env", Err: fs.ErrNotExist}
+
+	tests := []struct {
+		name     string
+		envFiles []string
+		loadErrs map[string]error
+		err      error
+	}{
+		{"Overlay and base env files", []string{".env.staging", ".env"}, map[string]error{".env.staging": nil, ".env": nil}, nil},
+		{"Missing overlay env file", []string{".env.staging", ".env"}, map[string]error{".env.staging": overlayErr, ".env": nil}, nil},
+		{"Missing base env file", []string{".env.staging", ".env"}, map[string]error{".env.staging": nil, ".env": baseErr}, baseErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(cleanup)
+			for file, err := range tt.loadErrs {
+				mockGodotenv.On("Load", []string{file}).Return(err)
+			}
+			err := loadEnvironmentEnvFiles(tt.envFiles, false)
+			assert.Equal(t, tt.err, err)
+			mockGodotenv.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWrapEnvFileError(t *testing.T) {
+	notExistErr := &fs.PathError{Op: "open", Path: "missing.env", Err: fs.ErrNotExist}
+	permissionErr := &fs.PathError{Op: "open", Path: "locked.env", Err: fs.ErrPermission}
+	parseErr := errors.New("unexpected character \"-\" in variable name")
+
+	tests := []struct {
+		name     string
+		filePath []string
+		err      error
+		target   error
+		expected string
+	}{
+		{"Missing env file", []string{"missing.env"}, notExistErr, fs.ErrNotExist, "invalid env path args: open missing.env: file does not exist"},
+		{"Permission denied", []string{"locked.env"}, permissionErr, fs.ErrPermission, "invalid env path args: open locked.env: permission denied"},
+		{"Parse error in default env file", nil, parseErr, parseErr, "invalid env path args: .env: unexpected character \"-\" in variable name"},
+		{"Parse error in multiple env files", []string{"a.env", "b.env"}, parseErr, parseErr, "invalid env path args: a.env, b.env: unexpected character \"-\" in variable name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := wrapEnvFileError(tt.filePath, tt.err)
+			assert.ErrorIs(t, err, ErrInvalidEnvPathArgs)
+			assert.ErrorIs(t, err, tt.target)
+			assert.Equal(t, tt.expected, err.Error())
+		})
+	}
+}
+
+func TestSettingsLookupEnv(t *testing.T) {
+	t.Setenv("HOST", "localhost")
+	envContent := map[string]string{"HOST": "contenthost", "PORT": "8080"}
+
+	tests := []struct {
+		name          string
+		overload      bool
+		envName       string
+		expectedValue string
+		expectedOk    bool
+	}{
+		{"Process env takes precedence", false, "HOST", "localhost", true},
+		{"Env content takes precedence with overload", true, "HOST", "contenthost", true},
+		{"Env content fallback", false, "PORT", "8080", true},
+		{"Missing env var", false, "MISSING", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := loadSettings(WithOverloadEnv(tt.overload))
+			s.envContent = envContent
+			value, ok, err := s.lookupEnv(tt.envName)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedValue, value)
+			assert.Equal(t, tt.expectedOk, ok)
+		})
+	}
+}
+
+func TestEnvMapWithPrefix(t *testing.T) {
+	t.Setenv("ENVMAP_HOST", "localhost")
+	t.Setenv("ENVMAP_PORT", "8080")
+	t.Setenv("ENVMAP_EMPTY", "")
+	t.Setenv("OTHER_ENVMAP_HOST", "other")
+
+	assert.Equal(t, map[string]string{
+		"ENVMAP_HOST":  "localhost",
+		"ENVMAP_PORT":  "8080",
+		"ENVMAP_EMPTY": "",
+	}, EnvMapWithPrefix("ENVMAP_"))
+	assert.Equal(t, map[string]string{
+		"HOST":  "localhost",
+		"PORT":  "8080",
+		"EMPTY": "",
+	}, EnvMapTrimPrefix("ENVMAP_"))
+	assert.Empty(t, EnvMapWithPrefix("ENVMAP_MISSING_"))
+}
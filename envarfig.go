@@ -1,12 +1,29 @@
 package envarfig
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"reflect"
+	"sort"
 	"sync"
 )
 
 var cachedConfigs sync.Map // Map to store cached configurations
 
+// Validator is implemented by the configs validating themselves (e.g. cross field checks),
+// Validate is called after all the fields are loaded
+type Validator interface {
+	Validate() error
+}
+
+// cachedConfig is a cached configuration along with the hash of its source env vars
+type cachedConfig struct {
+	config     any
+	envSources []envSource
+	envHash    uint64
+}
+
 /*
 args:
   - envConfig: a pointer to a struct
@@ -17,20 +34,193 @@ returns:
 */
 func LoadEnv[T any](envConfig *T, options ...option) error {
 	if envConfig == nil {
-		return errNilConfig
+		return ErrNilConfig
+	}
+
+	// Load the settings
+	return loadEnv(envConfig, loadSettings(options...))
+}
+
+/*
+info: loads only the named fields of the struct, leaving the other fields untouched
+(e.g. hot-reloading the log level), partial loads are never cached
+
+args:
+  - envConfig: a pointer to a struct
+  - fields: the names of the struct fields to load
+  - options: variadic options for configuration (e.g., env file paths, auto-load settings)
+
+returns:
+  - error: an error if any
+*/
+func LoadEnvFields[T any](envConfig *T, fields []string, options ...option) error {
+	if envConfig == nil {
+		return ErrNilConfig
+	}
+
+	// Check the fields exist in the struct
+	structType := reflect.TypeOf(envConfig).Elem()
+	if structType.Kind() != reflect.Struct {
+		return ErrConfigNotPtrToStruct
+	}
+	fieldFilter := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if _, ok := structType.FieldByName(field); !ok {
+			return fmt.Errorf("unknown field %s in %s", field, structType)
+		}
+		fieldFilter[field] = true
 	}
 
 	// Load the settings
 	settings := loadSettings(options...)
+	settings.CacheConfig = false
+	settings.fieldFilter = fieldFilter
+	return loadEnv(envConfig, settings)
+}
+
+/*
+info: loads the env vars like LoadEnv, the context is passed to the env source lookups
+so a slow remote source (e.g. Vault, SSM) can be cancelled
+
+args:
+  - ctx: the context of the env source lookups, nil is context.Background
+  - envConfig: a pointer to a struct
+  - options: variadic options for configuration (e.g., env file paths, auto-load settings)
+
+returns:
+  - error: an error if any
+*/
+func LoadEnvContext[T any](ctx context.Context, envConfig *T, options ...option) error {
+	if envConfig == nil {
+		return ErrNilConfig
+	}
+
+	// Load the settings, a nil context is not cancelled like context.Background
+	settings := loadSettings(options...)
+	if ctx != nil {
+		settings.ctx = ctx
+	}
+	return loadEnv(envConfig, settings)
+}
+
+/*
+info: loads the env vars like LoadEnv and returns the source of each env var for auditing,
+the sources are "env", "envfile", "default", "defaults" or "unset", the load is never cached
+and the env files are read without being loaded into the process env so their values are told apart
+
+useage: sources, err := LoadEnvWithSources(&config) returns map[PORT:env HOST:default]
+
+args:
+  - envConfig: a pointer to a struct
+  - options: variadic options for configuration (e.g., env file paths, auto-load settings)
+
+returns:
+  - map[string]string: the source of the value by env var name
+  - error: an error if any
+*/
+func LoadEnvWithSources[T any](envConfig *T, options ...option) (map[string]string, error) {
+	if envConfig == nil {
+		return nil, ErrNilConfig
+	}
+
+	// Load the settings, a cached config has no sources and the loaded env files are part of the process env
+	settings := loadSettings(options...)
+	settings.CacheConfig = false
+	settings.readEnvFiles = true
+	settings.fieldSources = make(map[string]string)
+	if err := loadEnv(envConfig, settings); err != nil {
+		return nil, err
+	}
+	return settings.fieldSources, nil
+}
+
+/*
+info: runs the full load of the struct (types, required fields, defaults and the Validator)
+against an internal zero value, for pre-flight checks like a "config check" command, the
+errors of all the fields are joined and the env files are read without being loaded into the process env
+
+useage: ValidateEnv[Config](WithEnvFiles(".env.production"))
+
+args:
+  - options: variadic options for configuration (e.g., env file paths, auto-load settings)
+
+returns:
+  - error: the joined field errors or the load error if any
+*/
+func ValidateEnv[T any](options ...option) error {
+	// a dry run must neither be served from nor stored in the cache nor change the process env
+	settings := loadSettings(options...)
+	settings.CacheConfig = false
+	settings.readEnvFiles = true
+	settings.joinFieldErrors = true
+	var envConfig T
+	return loadEnv(&envConfig, settings)
+}
+
+/*
+info: lists the struct types of the cached configs, for diagnosing which config a load
+with caching enabled is served from
+
+useage: CacheKeys() returns [github.com/acme/app/config.Config]
+
+returns:
+  - []string: the sorted struct types, named by their package path and name
+*/
+func CacheKeys() []string {
+	var keys []string
+	cachedConfigs.Range(func(key, _ any) bool {
+		structType := key.(reflect.Type)
+		name := structType.String()
+		if structType.Name() != "" && structType.PkgPath() != "" {
+			name = structType.PkgPath() + "." + structType.Name()
+		}
+		keys = append(keys, name)
+		return true
+	})
+	sort.Strings(keys)
+	return keys
+}
+
+func loadEnv[T any](envConfig *T, settings *settings) error {
+	if err := settings.resolveEnvironment(); err != nil {
+		return err
+	}
+
+	// a config of the defaults only must not be served from or stored in the cache
+	if settings.DefaultsOnly {
+		settings.CacheConfig = false
+	}
+	// the command-line flags are not part of the cached env sources
+	if settings.FlagSet != nil {
+		settings.CacheConfig = false
+	}
+	// the transforms and name strategies can not be told apart in the cache hash
+	if settings.hasValueFuncs() {
+		settings.CacheConfig = false
+	}
+	// the env files are a source of their own with a precedence, so they are read instead of loaded
+	if settings.Precedence != nil {
+		settings.readEnvFiles = true
+	}
 
 	// Get the type of the struct to use as a cache key
 	structType := reflect.TypeOf(envConfig).Elem()
 
+	// the env content is read before the cache check, as its values are part of the cache hash
+	if err := readEnvContent(settings); err != nil {
+		return err
+	}
+
 	// Check if caching is enabled and the struct is already cached
 	if settings.CacheConfig {
-		if cachedConfig, ok := cachedConfigs.Load(structType); ok {
-			*envConfig = cachedConfig.(T) // Load from cache
-			return nil
+		if cached, ok := cachedConfigs.Load(structType); ok {
+			// the cached config is stale when any of its source env vars or the options changed
+			entry := cached.(cachedConfig)
+			if entry.envHash == hashEnvSources(settings, entry.envSources) {
+				*envConfig = entry.config.(T) // Load from cache
+				settings.logCachedConfig(structType)
+				return nil
+			}
 		}
 	}
 
@@ -39,18 +229,34 @@ func LoadEnv[T any](envConfig *T, options ...option) error {
 
 	// Ensure the struct is only loaded once
 	once.Do(func() {
-		// Load the env file
-		err = loadEnvFile(settings.AutoLoadEnv, settings.EnvFiles)
-		if err != nil {
-			err = errInvalidEnvPathArgs
+		if err = loadEnvFiles(settings); err != nil {
 			return
 		}
 
 		// Parse the environment variables into the struct
-		err = parseEnvVar(envConfig)
-		if err == nil && settings.CacheConfig {
+		if err = parseEnvVar(envConfig, settings); err != nil {
+			return
+		}
+
+		// Check the env vars with the configured prefix are all used
+		if err = checkUnusedEnvVars(settings); err != nil {
+			return
+		}
+
+		// Validate the config if it implements the Validator interface
+		if validator, ok := any(envConfig).(Validator); ok {
+			if err = validator.Validate(); err != nil {
+				return
+			}
+		}
+
+		if settings.CacheConfig {
 			// Cache the struct configuration
-			cachedConfigs.Store(structType, *envConfig)
+			cachedConfigs.Store(structType, cachedConfig{
+				config:     *envConfig,
+				envSources: settings.envSources,
+				envHash:    hashEnvSources(settings, settings.envSources),
+			})
 		}
 	})
 
@@ -60,3 +266,42 @@ func LoadEnv[T any](envConfig *T, options ...option) error {
 
 	return nil
 }
+
+/*
+Read the env content of the settings, the env content is parsed instead of loading the env
+files, the env files themselves are read into it when the process env must not be changed
+and nothing is read when only the defaults are used
+*/
+func readEnvContent(settings *settings) error {
+	var err error
+	switch {
+	case settings.DefaultsOnly:
+	case settings.EnvReader != nil:
+		var content []byte
+		if content, err = settings.EnvReader.read(settings.MaxEnvFileSize); err == nil {
+			settings.envContent, err = parseEnvContent(bytes.NewReader(content), settings.MaxEnvFileSize)
+		}
+	case settings.LayeredFiles != nil:
+		settings.envContent, err = readLayeredEnvFiles(settings)
+	case settings.readEnvFiles:
+		settings.envContent, err = readSettingsEnvFiles(settings)
+	}
+	return err
+}
+
+/*
+Load the env files of the settings into the process env, unless the env content is read
+instead or only the defaults are used
+*/
+func loadEnvFiles(settings *settings) error {
+	if !settings.DefaultsOnly && settings.EnvReader == nil && settings.LayeredFiles == nil && !settings.readEnvFiles {
+		if err := loadSettingsEnvFiles(settings); err != nil {
+			return err
+		}
+	}
+	// capture the process env once the env files are loaded into it
+	if settings.EnvSnapshot {
+		settings.EnvSource = newSnapshotEnvSource()
+	}
+	return nil
+}
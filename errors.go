@@ -1,17 +1,84 @@
 package envarfig
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
 
-// errors
+// errors, matched with errors.Is
 var (
-	// Error if config is nil
-	errNilConfig = errors.New("env config is nil")
-	// Error if config is not a pointer to a struct
-	errConfigNotPtrToStruct = errors.New("config must be a pointer to a struct")
-	// Error if tag is not found
-	errTagNotFound = errors.New("tag not found")
-	// Error if env file is invalid type
-	errInvalidEnvPathArgs = errors.New("invalid env path args")
-	// Error if autoload is false and file path is not nil
-	errAutoLoadFalseFilePath = errors.New("autoload should not be false when file path is not nil")
+	// ErrNilConfig is returned when the config is nil
+	ErrNilConfig = errors.New("env config is nil")
+	// ErrConfigNotPtrToStruct is returned when the config is not a pointer to a struct
+	ErrConfigNotPtrToStruct = errors.New("config must be a pointer to a struct")
+	// ErrTagNotFound is returned when a field has no env tag
+	ErrTagNotFound = errors.New("tag not found")
+	// ErrInvalidEnvPathArgs is returned when the env file paths are of an invalid type
+	ErrInvalidEnvPathArgs = errors.New("invalid env path args")
+	// ErrAutoLoadFalseFilePath is returned when autoload is false and the file path is not nil
+	ErrAutoLoadFalseFilePath = errors.New("autoload should not be false when file path is not nil")
+	// ErrEnvFileTooLarge is returned when an env file or the env content exceeds WithMaxEnvFileSize
+	ErrEnvFileTooLarge = errors.New("env file too large")
+	// ErrEnvKeysUnsupported is returned when the env var names are matched against an env source
+	// not implementing EnvKeyLister
+	ErrEnvKeysUnsupported = errors.New("env source does not list its env var names")
+	// ErrEmbeddedStructTag is returned when an embedded struct has an env tag without the json option,
+	// the embedded structs are promoted untagged
+	ErrEmbeddedStructTag = errors.New("embedded struct must not have an env tag")
+	// ErrUnsupportedType is matched by the errors of the field, slice/array element and map
+	// key and value types that can not be loaded, the message names the unsupported kind
+	ErrUnsupportedType = errors.New("unsupported type")
 )
+
+// unsupportedTypeError is an unsupported type error, e.g. "unsupported field type: struct"
+type unsupportedTypeError struct {
+	what string
+	kind reflect.Kind
+}
+
+func (e *unsupportedTypeError) Error() string {
+	return fmt.Sprintf("%s: %s", e.what, e.kind)
+}
+
+func (e *unsupportedTypeError) Is(target error) bool {
+	return target == ErrUnsupportedType
+}
+
+// fieldError is a field load error with the custom message of the field's msg tag,
+// the underlying error is kept for errors.Is and errors.As
+type fieldError struct {
+	msg string
+	err error
+}
+
+func (e *fieldError) Error() string {
+	return e.msg
+}
+
+func (e *fieldError) Unwrap() error {
+	return e.err
+}
+
+// withFieldMessage replaces the message of the error with the custom message if any
+func withFieldMessage(msg string, err error) error {
+	if msg == "" || err == nil {
+		return err
+	}
+	return &fieldError{msg: msg, err: err}
+}
+
+// formatFieldError renders the error of the field with the error formatter if any, or else prefixes it
+// with its struct field and env var names (e.g. "field Config.Port (PORT): ...") with the field names in errors option
+func (s *settings) formatFieldError(structType reflect.Type, fieldName string, envName string, err error) error {
+	if err == nil || (s.ErrorFormatter == nil && !s.FieldNamesInErrors) {
+		return err
+	}
+	if structType.Name() != "" {
+		fieldName = structType.Name() + "." + fieldName
+	}
+	if s.ErrorFormatter != nil {
+		return s.ErrorFormatter(fieldName, envName, err)
+	}
+	return fmt.Errorf("field %s (%s): %w", fieldName, envName, err)
+}
@@ -0,0 +1,49 @@
+package envarfig
+
+import (
+	"fmt"
+	"os"
+)
+
+/*
+info: expands the ${VAR} and $VAR references of the default value against the env source,
+the referenced values are substituted as is like os.Expand and "$$" is a literal "$"
+
+useage: expandDefaultValue("http://${HOST}:${PORT}", settings)
+
+args:
+  - defaultValue: the default value of the field
+  - settings: the settings of the env source and the unknown reference behaviour
+*/
+func expandDefaultValue(defaultValue string, settings *settings) (string, error) {
+	return expandEnvRefs(defaultValue, settings)
+}
+
+// expandEnvRefs expands the references of the value one level, so the "$" of the referenced values are kept
+func expandEnvRefs(value string, settings *settings) (string, error) {
+	var expandErr error
+	expanded := os.Expand(value, func(name string) string {
+		if expandErr != nil {
+			return ""
+		}
+		// "$$" escapes a literal "$"
+		if name == "$" {
+			return "$"
+		}
+		refValue, exist, err := settings.lookupEnv(name)
+		if err != nil {
+			expandErr = err
+			return ""
+		}
+		// the referenced env var is a source of the config too
+		settings.envSources = append(settings.envSources, envSource{name: name})
+		if !exist && settings.ErrorOnUnknownRef {
+			expandErr = fmt.Errorf("unknown reference to %s", name)
+		}
+		return refValue
+	})
+	if expandErr != nil {
+		return "", expandErr
+	}
+	return expanded, nil
+}
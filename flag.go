@@ -0,0 +1,81 @@
+package envarfig
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+)
+
+// flagField is a loaded struct field which is overridden by its command-line flag when it is set
+type flagField struct {
+	typ       reflect.Type
+	fieldName string
+	value     reflect.Value
+	tagProp   tagProperties
+}
+
+// flagName derives the name of the command-line flag from the env var name, e.g. DB_HOST is -db-host
+func flagName(envName string) string {
+	return strings.ReplaceAll(strings.ToLower(envName), "_", "-")
+}
+
+// isFlagSet reports whether the flag of the env var was explicitly set on the command line
+func (s *settings) isFlagSet(envName string) bool {
+	if s.FlagSet == nil {
+		return false
+	}
+	name := flagName(envName)
+	set := false
+	s.FlagSet.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// trackFlagField records the field with its flag set for the flag override
+func (s *settings) trackFlagField(typ reflect.Type, fieldName string, value reflect.Value, tagProp tagProperties) {
+	if s.flagFields == nil {
+		s.flagFields = make(map[string]flagField)
+	}
+	s.flagFields[flagName(tagProp.EnvName)] = flagField{typ, fieldName, value, tagProp}
+}
+
+/*
+Override the loaded fields with the flags explicitly set on the command line, the flag
+values are parsed like the env var values and the flags of no field are ignored
+*/
+func setFlagValues(settings *settings) error {
+	if settings.FlagSet == nil {
+		return nil
+	}
+	var err error
+	settings.FlagSet.Visit(func(f *flag.Flag) {
+		field, ok := settings.flagFields[f.Name]
+		if !ok || err != nil {
+			return
+		}
+		err = setFlagFieldValue(field, f.Value.String(), settings)
+	})
+	return err
+}
+
+// setFlagFieldValue sets the field to the flag value, the flag values are never expanded
+func setFlagFieldValue(field flagField, flagValue string, settings *settings) error {
+	tagProp := field.tagProp
+	tagProp.setUnset(false)
+	tagProp.setExpandVars(nil)
+	settings.trackFieldValue(tagProp.EnvName, flagValue)
+
+	var err error
+	if converter, ok := lookupFieldConverter(field.typ, field.fieldName); ok {
+		err = setFieldConverterValue(field.value, tagProp, flagValue, converter)
+	} else {
+		err = setEnvVarValues(field.value, tagProp, flagValue)
+	}
+	if err != nil {
+		return settings.formatFieldError(field.typ, field.fieldName, tagProp.EnvName, withFieldMessage(tagProp.Message, err))
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package envarfig
+
+import (
+	"reflect"
+	"time"
+)
+
+/*
+info: reads a single env var without a struct, converted like a field of the same type
+(e.g. durations, slices and maps), the default is returned when the env var is not set, the env files
+are read without being loaded into the process env
+
+useage: Get("WORKERS", 4, WithLenient(true))
+
+args:
+  - name: the name of the env var
+  - defaultValue: the value returned when the env var is not set
+  - options: variadic options for configuration (e.g., env source, env files)
+
+returns:
+  - T: the converted value or the default
+  - error: an error if any, returned with the default
+*/
+func Get[T any](name string, defaultValue T, options ...option) (T, error) {
+	// the env files are read without changing the process env on each lookup
+	settings := loadSettings(options...)
+	settings.readEnvFiles = true
+	if err := settings.resolveEnvironment(); err != nil {
+		return defaultValue, err
+	}
+	if err := readEnvContent(settings); err != nil {
+		return defaultValue, err
+	}
+	if err := loadEnvFiles(settings); err != nil {
+		return defaultValue, err
+	}
+
+	envValue, exist, err := settings.lookupEnv(name)
+	if err != nil || !exist {
+		return defaultValue, err
+	}
+
+	tagProp, err := parseTagAndTagValues("")
+	if err != nil {
+		return defaultValue, err
+	}
+	tagProp.setEnvName(name)
+	tagProp.setTrimQuotes(settings.TrimQuotes)
+	tagProp.setBoolWords(settings.Lenient)
+	tagProp.setIntBools(settings.LenientBool)
+
+	var value T
+	fieldValue := reflect.ValueOf(&value).Elem()
+	if settings.Lenient {
+		envValue = coerceLenientValue(fieldValue.Kind(), envValue)
+	}
+	if err := setEnvVarValues(fieldValue, tagProp, envValue); err != nil {
+		return defaultValue, err
+	}
+	return value, nil
+}
+
+// GetString reads the string env var, returning the default when it is not set or can not be read
+func GetString(name string, defaultValue string, options ...option) string {
+	value, err := Get(name, defaultValue, options...)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// GetInt reads the int env var, returning the default when it is not set
+func GetInt(name string, defaultValue int, options ...option) (int, error) {
+	return Get(name, defaultValue, options...)
+}
+
+// GetBool reads the bool env var, returning the default when it is not set
+func GetBool(name string, defaultValue bool, options ...option) (bool, error) {
+	return Get(name, defaultValue, options...)
+}
+
+// GetFloat64 reads the float64 env var, returning the default when it is not set
+func GetFloat64(name string, defaultValue float64, options ...option) (float64, error) {
+	return Get(name, defaultValue, options...)
+}
+
+// GetDuration reads the time.Duration env var (e.g. "30s"), returning the default when it is not set
+func GetDuration(name string, defaultValue time.Duration, options ...option) (time.Duration, error) {
+	return Get(name, defaultValue, options...)
+}
@@ -0,0 +1,106 @@
+package envarfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// isGlobEnvName reports whether the env name is a glob collecting the matching env vars into a map, e.g. FEATURE_*
+func isGlobEnvName(envName string) bool {
+	return strings.Contains(envName, "*")
+}
+
+/*
+info: loads the map from the env vars matching the glob, the part of the name matched by
+the "*" is the map key, the default is parsed as a map when no env var matches
+
+useage: FEATURE_SEARCH=true FEATURE_BETA=false loads map[SEARCH:true BETA:false] into a map[string]bool field tagged env:"FEATURE_*"
+
+args:
+  - fieldValue: the map field
+  - tagProp: the tag properties of the field
+  - settings: the settings of the parse
+*/
+func setGlobMapValues(fieldValue reflect.Value, tagProp tagProperties, settings *settings) error {
+	if fieldValue.Kind() != reflect.Map {
+		return fmt.Errorf("glob env var %s requires a map field, got %s", tagProp.EnvName, fieldValue.Kind())
+	}
+	prefix, suffix, _ := strings.Cut(tagProp.EnvName, "*")
+	if strings.Contains(suffix, "*") {
+		return fmt.Errorf("invalid glob env var %s: only one * is supported", tagProp.EnvName)
+	}
+
+	// the matching env vars are tracked so a new or changed one invalidates the cached config
+	settings.envSources = append(settings.envSources, envSource{name: tagProp.EnvName, glob: true})
+	names, err := globEnvNames(tagProp.EnvName, settings)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		if tagProp.Required && tagProp.DefaultValue == "" {
+			return withFieldMessage(tagProp.Message, fmt.Errorf("required environment variable %s not found", tagProp.EnvName))
+		}
+		fieldValue.SetZero()
+		if tagProp.DefaultValue == "" {
+			return nil
+		}
+		return withFieldMessage(tagProp.Message, setEnvVarValues(fieldValue, tagProp, tagProp.DefaultValue))
+	}
+
+	mapType := fieldValue.Type()
+	values := reflect.MakeMapWithSize(mapType, len(names))
+	for _, name := range names {
+		envValue, _, err := settings.lookupEnv(name)
+		if err != nil {
+			return err
+		}
+		keyRaw := name[len(prefix) : len(name)-len(suffix)]
+		key := reflect.New(mapType.Key()).Elem()
+		if err := convertScalar(key, keyRaw, tagProp); err != nil {
+			return withFieldMessage(tagProp.Message, mapScalarError(err, "key", keyRaw, key.Kind()))
+		}
+		// the values are parsed like the fields, with the errors naming the matching env var
+		valueProp := tagProp
+		valueProp.setEnvName(name)
+		value := reflect.New(mapType.Elem()).Elem()
+		if err := setEnvVarValues(value, valueProp, envValue); err != nil {
+			return withFieldMessage(tagProp.Message, err)
+		}
+		values.SetMapIndex(key, value)
+	}
+	fieldValue.Set(values)
+	return nil
+}
+
+// globEnvNames returns the sorted names of the env vars matching the glob, the "*" matches at least one character
+func globEnvNames(glob string, settings *settings) ([]string, error) {
+	// the environment is ignored when only the defaults are used
+	if settings.DefaultsOnly {
+		return nil, nil
+	}
+	prefix, suffix, _ := strings.Cut(glob, "*")
+	envNames, err := settings.envNames()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var names []string
+	for _, name := range envNames {
+		if seen[name] || len(name) <= len(prefix)+len(suffix) || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		// the names of the env sources left out of the precedence do not match
+		if _, exist, err := settings.lookupEnv(name); err != nil || !exist {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,123 @@
+package envarfig
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// isIndexedStructSliceType reports whether the slice of structs can be loaded from indexed env vars (e.g. SERVERS_0_HOST)
+func isIndexedStructSliceType(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Slice || typ.Elem().Kind() != reflect.Struct {
+		return false
+	}
+	elemType := typ.Elem()
+	return !isPairSliceType(typ) && !isSQLNullType(elemType) && !reflect.PointerTo(elemType).Implements(textUnmarshalerType)
+}
+
+/*
+info: loads the slice of structs from the indexed env vars, each element is parsed from the
+env vars prefixed with the env name and its index, the indices must start at 0 without gaps
+
+useage: SERVERS_0_HOST=a SERVERS_0_PORT=80 SERVERS_1_HOST=b loads the 2 elements of a []Server field tagged env:"SERVERS"
+
+args:
+  - fieldValue: the slice field
+  - tagProp: the tag properties of the field
+  - settings: the settings of the parse
+*/
+func setIndexedStructSliceValues(fieldValue reflect.Value, tagProp tagProperties, settings *settings) error {
+	// the indices are tracked so a new element invalidates the cached config
+	settings.envSources = append(settings.envSources, envSource{name: tagProp.EnvName, indexed: true})
+	indices, err := indexedEnvIndices(tagProp.EnvName, settings)
+	if err != nil {
+		return err
+	}
+	if len(indices) == 0 {
+		if tagProp.Required {
+			return withFieldMessage(tagProp.Message, fmt.Errorf("required environment variable %s not found", tagProp.EnvName))
+		}
+		fieldValue.SetZero()
+		return nil
+	}
+
+	// the fields of the elements are all loaded, whatever the fields of a partial load
+	envPrefix, fieldFilter := settings.envPrefix, settings.fieldFilter
+	settings.fieldFilter = nil
+	defer func() { settings.envPrefix, settings.fieldFilter = envPrefix, fieldFilter }()
+
+	elements := reflect.MakeSlice(fieldValue.Type(), len(indices), len(indices))
+	for i, index := range indices {
+		if index != i {
+			return fmt.Errorf("missing index %d of %s", i, tagProp.EnvName)
+		}
+		settings.envPrefix = fmt.Sprintf("%s_%d_", tagProp.EnvName, i)
+		if err := parseStructFields(elements.Index(i), settings); err != nil {
+			return err
+		}
+	}
+	fieldValue.Set(elements)
+	return nil
+}
+
+// indexedEnvIndices returns the sorted indices of the indexed env vars of the name, e.g. 0 and 1 for SERVERS_0_HOST and SERVERS_1_HOST
+func indexedEnvIndices(envName string, settings *settings) ([]int, error) {
+	// the environment is ignored when only the defaults are used
+	if settings.DefaultsOnly {
+		return nil, nil
+	}
+	names, err := settings.envNames()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[int]bool)
+	for _, name := range names {
+		rest, ok := strings.CutPrefix(name, envName+"_")
+		if !ok {
+			continue
+		}
+		indexValue, _, ok := strings.Cut(rest, "_")
+		if !ok {
+			continue
+		}
+		// only the canonical indices count, e.g. not 01 or +1
+		index, err := strconv.Atoi(indexValue)
+		if err != nil || index < 0 || strconv.Itoa(index) != indexValue {
+			continue
+		}
+		seen[index] = true
+	}
+	indices := make([]int, 0, len(seen))
+	for index := range seen {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+	return indices, nil
+}
+
+// prefixTagProperties prefixes the env var names of the field, e.g. HOST -> SERVERS_0_HOST for the indexed struct slice elements
+func prefixTagProperties(tagProp tagProperties, prefix string) tagProperties {
+	prefixNames := func(names []string) []string {
+		prefixed := make([]string, len(names))
+		for i, name := range names {
+			prefixed[i] = prefix + name
+		}
+		return prefixed
+	}
+	tagProp.setEnvName(prefix + tagProp.EnvName)
+	tagProp.setAliases(prefixNames(tagProp.Aliases))
+	tagProp.setDeprecated(prefixNames(tagProp.Deprecated))
+	// the groups and the conditions are scoped to the element
+	if tagProp.Group != "" {
+		tagProp.setGroup(prefix + tagProp.Group)
+	}
+	if tagProp.RequiredIf != "" {
+		tagProp.setRequiredIf(prefix+tagProp.RequiredIf, tagProp.RequiredIfValue)
+	}
+	return tagProp
+}
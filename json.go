@@ -0,0 +1,50 @@
+package envarfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+/*
+info: parses the env var value as json into the field, map values are parsed
+one by one so the error names the key that failed
+
+args:
+  - fieldValue: the field to set
+  - envName: the name of the env var
+  - envValue: the json env var value
+*/
+func setEnvVarJSONValues(fieldValue reflect.Value, envName string, envValue string) error {
+	if fieldValue.Kind() != reflect.Map {
+		if err := json.Unmarshal([]byte(envValue), fieldValue.Addr().Interface()); err != nil {
+			return fmt.Errorf("failed to parse %s as json: %w", envName, err)
+		}
+		return nil
+	}
+
+	var rawValues map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(envValue), &rawValues); err != nil {
+		return fmt.Errorf("failed to parse %s as json: %w", envName, err)
+	}
+
+	mapType := fieldValue.Type()
+	newMap := reflect.MakeMapWithSize(mapType, len(rawValues))
+	for key, rawValue := range rawValues {
+		mapKey := reflect.New(mapType.Key())
+		if mapType.Key().Kind() == reflect.String {
+			mapKey.Elem().SetString(key)
+		} else if err := json.Unmarshal([]byte(key), mapKey.Interface()); err != nil {
+			return fmt.Errorf("failed to parse map key %s of %s as json: %w", key, envName, err)
+		}
+
+		mapValue := reflect.New(mapType.Elem())
+		if err := json.Unmarshal(rawValue, mapValue.Interface()); err != nil {
+			return fmt.Errorf("failed to parse map value for key %s of %s as json: %w", key, envName, err)
+		}
+		newMap.SetMapIndex(mapKey.Elem(), mapValue.Elem())
+	}
+
+	fieldValue.Set(newMap)
+	return nil
+}
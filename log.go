@@ -0,0 +1,41 @@
+package envarfig
+
+import (
+	"log/slog"
+	"reflect"
+)
+
+// redactedValue replaces the values of the secret fields in the logs
+const redactedValue = "[REDACTED]"
+
+/*
+Log the resolved value of the field at the debug level with its source (e.g. "env" or "default"),
+the source is "unset" when there is no value and nothing is logged without a logger
+*/
+func (s *settings) logFieldValue(typ reflect.Type, fieldName string, tagProp tagProperties, foundName string, source ValueSource, envValue string) {
+	if s.Logger == nil || !s.Logger.Enabled(s.ctx, slog.LevelDebug) {
+		return
+	}
+	if tagProp.Secret {
+		envValue = redactedValue
+	}
+	s.Logger.LogAttrs(s.ctx, slog.LevelDebug, "envarfig: field resolved",
+		slog.String("struct", typ.String()),
+		slog.String("field", fieldName),
+		slog.String("env", foundName),
+		slog.String("source", source.String()),
+		slog.String("value", envValue),
+		slog.Bool("cached", false),
+	)
+}
+
+// logCachedConfig logs at the debug level that the config is served from the cache
+func (s *settings) logCachedConfig(typ reflect.Type) {
+	if s.Logger == nil {
+		return
+	}
+	s.Logger.LogAttrs(s.ctx, slog.LevelDebug, "envarfig: config loaded from cache",
+		slog.String("struct", typ.String()),
+		slog.Bool("cached", true),
+	)
+}
@@ -0,0 +1,31 @@
+package envarfig
+
+import (
+	"strings"
+	"unicode"
+)
+
+/*
+info: converts the field name to SCREAMING_SNAKE_CASE, the default name strategy
+
+useage: toScreamingSnakeCase("DatabaseURL") returns "DATABASE_URL"
+
+args:
+  - fieldName: the name of the struct field
+*/
+func toScreamingSnakeCase(fieldName string) string {
+	runes := []rune(fieldName)
+	var name strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			// split on "aB", "1B" and the end of an acronym as in "URLPath"
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				name.WriteByte('_')
+			}
+		}
+		name.WriteRune(unicode.ToUpper(r))
+	}
+	return name.String()
+}
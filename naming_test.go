@@ -0,0 +1,30 @@
+//go:build unit
+
+package envarfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToScreamingSnakeCase(t *testing.T) {
+	tests := []struct {
+		fieldName string
+		expected  string
+	}{
+		{"Host", "HOST"},
+		{"DatabaseURL", "DATABASE_URL"},
+		{"APIKey", "API_KEY"},
+		{"HTTPPort2", "HTTP_PORT2"},
+		{"V2Key", "V2_KEY"},
+		{"already_snake", "ALREADY_SNAKE"},
+		{"maxIdleConns", "MAX_IDLE_CONNS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fieldName, func(t *testing.T) {
+			assert.Equal(t, tt.expected, toScreamingSnakeCase(tt.fieldName))
+		})
+	}
+}
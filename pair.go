@@ -0,0 +1,45 @@
+package envarfig
+
+import (
+	"reflect"
+)
+
+// Pair is a key value entry of an ordered map, a []Pair[K, V] field is parsed with the
+// map syntax (e.g. {auth:jwt,cors:strict}) keeping the order of the entries as written
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+func (Pair[K, V]) isPair() {}
+
+// pairMarker is implemented by every Pair type
+type pairMarker interface {
+	isPair()
+}
+
+var pairMarkerType = reflect.TypeOf((*pairMarker)(nil)).Elem()
+
+// isPairSliceType reports whether the type is a slice of Pair
+func isPairSliceType(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Struct && typ.Elem().Implements(pairMarkerType)
+}
+
+func setEnvVarPairValues(fieldValue reflect.Value, envName string, envValue string, tagProp tagProperties) error {
+	pairType := fieldValue.Type().Elem()
+	keyField, _ := pairType.FieldByName("Key")
+	valueField, _ := pairType.FieldByName("Value")
+
+	entries, err := parseMapEntries(keyField.Type, valueField.Type, envName, envValue, tagProp)
+	if err != nil {
+		return err
+	}
+	pairs := reflect.MakeSlice(fieldValue.Type(), len(entries), len(entries))
+	for i, entry := range entries {
+		pairs.Index(i).FieldByIndex(keyField.Index).Set(entry.key)
+		pairs.Index(i).FieldByIndex(valueField.Index).Set(entry.value)
+	}
+
+	fieldValue.Set(pairs)
+	return nil
+}
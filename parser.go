@@ -1,11 +1,17 @@
 package envarfig
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"reflect"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // constants
@@ -14,417 +20,1442 @@ const (
 	defaultTagName = "env"
 )
 
+var compiledPatterns sync.Map // Map to store compiled regex patterns
+
+var parsedTags sync.Map // Map to store the parsed tags of the struct types
+
+var locationType = reflect.TypeOf((*time.Location)(nil))
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// multilineReplacer unescapes the escaped newlines, carriage returns, tabs and backslashes
+var multilineReplacer = strings.NewReplacer(`\\`, `\`, `\n`, "\n", `\r`, "\r", `\t`, "\t")
+
+// the slice types with a typed fast path
+var (
+	stringSliceType = reflect.TypeOf([]string(nil))
+	intSliceType    = reflect.TypeOf([]int(nil))
+	boolSliceType   = reflect.TypeOf([]bool(nil))
+)
+
+// the map type with a typed fast path
+var stringMapType = reflect.TypeOf(map[string]string(nil))
+
 type tagProperties struct {
-	EnvName      string
-	DefaultValue string
-	Delimiter    string
-	Required     bool
-	isString     bool
+	EnvName             string
+	Aliases             []string
+	Deprecated          []string
+	Group               string
+	Message             string
+	DefaultValue        string
+	Delimiter           string
+	ValueDelimiter      string
+	Required            bool
+	RequiredIf          string
+	RequiredIfValue     string
+	EnvironmentDefaults map[string]string
+	Pattern             string
+	Unique              bool
+	Versioned           bool
+	Encoding            []string
+	Forbidden           []string
+	JSON                bool
+	Base                int
+	SortOrder           string
+	ByteSize            bool
+	Percent             bool
+	Layout              string
+	NoTrim              bool
+	Secret              bool
+	Infer               bool
+	Multiline           bool
+	FromFile            bool
+	Unit                time.Duration
+	isString            bool
+	regex               *regexp.Regexp
+	expandVars          func(value string) (string, error)
+	uniqueMapKeys       bool
+	jsonMapFallback     bool
+	allowShortArray     bool
+	truncateArray       bool
+	ignoreTrailingDelim bool
+	emptyAsNil          bool
+	trimQuotes          bool
+	unset               bool
+	optional            bool
+	defaultSet          bool
+	boolWords           bool
+	intBools            bool
 }
 
 func (tp *tagProperties) setEnvName(envName string) {
 	tp.EnvName = envName
 }
+
+func (tp *tagProperties) setAliases(aliases []string) {
+	tp.Aliases = aliases
+}
+
+func (tp *tagProperties) setMessage(message string) {
+	tp.Message = message
+}
+
+func (tp *tagProperties) setGroup(group string) {
+	tp.Group = group
+}
+
+func (tp *tagProperties) setDeprecated(deprecated []string) {
+	tp.Deprecated = deprecated
+}
 func (tp *tagProperties) setDefaultValue(defaultValue string) {
 	tp.DefaultValue = defaultValue
 }
+func (tp *tagProperties) setEnvironmentDefault(environment string, defaultValue string) {
+	if tp.EnvironmentDefaults == nil {
+		tp.EnvironmentDefaults = make(map[string]string)
+	}
+	tp.EnvironmentDefaults[environment] = defaultValue
+}
 func (tp *tagProperties) setRequired(required bool) {
 	tp.Required = required
 }
+
+func (tp *tagProperties) setRequiredIf(envName string, value string) {
+	tp.RequiredIf = envName
+	tp.RequiredIfValue = value
+}
+
 func (tp *tagProperties) setDelimiter(s string) {
 	tp.Delimiter = s
 }
+func (tp *tagProperties) setValueDelimiter(s string) {
+	tp.ValueDelimiter = s
+}
 func (tp *tagProperties) setIsString() {
 	tp.isString = true
 }
+func (tp *tagProperties) setUnique(unique bool) {
+	tp.Unique = unique
+}
+func (tp *tagProperties) setVersioned(versioned bool) {
+	tp.Versioned = versioned
+}
+func (tp *tagProperties) setEncoding(stages []string) {
+	tp.Encoding = stages
+}
+func (tp *tagProperties) setForbidden(forbidden []string) {
+	tp.Forbidden = forbidden
+}
+func (tp *tagProperties) setJSON(json bool) {
+	tp.JSON = json
+}
+func (tp *tagProperties) setBase(base int) {
+	tp.Base = base
+}
+func (tp *tagProperties) setSortOrder(order string) {
+	tp.SortOrder = order
+}
+func (tp *tagProperties) setExpandVars(expandVars func(value string) (string, error)) {
+	tp.expandVars = expandVars
+}
 
-/*
-Parse the env var from the config struct
-*/
-func parseEnvVar[T any](config *T) error {
-	// get the value of the config
-	value := reflect.ValueOf(config)
+func (tp *tagProperties) setByteSize(byteSize bool) {
+	tp.ByteSize = byteSize
+}
 
-	// check if config is a pointer to a struct
-	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
-		return errConfigNotPtrToStruct
-	}
+func (tp *tagProperties) setPercent(percent bool) {
+	tp.Percent = percent
+}
 
-	// get the type of the config
-	value = value.Elem()
-	typ := value.Type()
+func (tp *tagProperties) setLayout(layout string) {
+	tp.Layout = layout
+}
 
-	// loop through the fields of the struct
-	for i := range typ.NumField() {
-		field := typ.Field(i)
-		tagValues := field.Tag.Get(defaultTagName) // get the tag value
+func (tp *tagProperties) setNoTrim(noTrim bool) {
+	tp.NoTrim = noTrim
+}
 
-		// check if the tag is empty
-		if tagValues == "" {
-			return errTagNotFound
-		}
+func (tp *tagProperties) setSecret(secret bool) {
+	tp.Secret = secret
+}
 
-		// get the field value
-		tagProp := parseTagAndTagValues(tagValues)
+func (tp *tagProperties) setInfer(infer bool) {
+	tp.Infer = infer
+}
 
-		//get and set the env var value
-		envValue, exist := os.LookupEnv(tagProp.EnvName)
-		if !exist {
-			// check if the field is required
-			if tagProp.Required && tagProp.DefaultValue == "" {
-				return fmt.Errorf("required environment variable %s not found", tagProp.EnvName)
-			}
-			// set the field value to the default value
-			envValue = tagProp.DefaultValue
-		}
-		// set the field value
-		fieldValue := value.Field(i)
-		if err := setEnvVarValues(fieldValue, tagProp, envValue); err != nil {
-			return err
-		}
-	}
+func (tp *tagProperties) setMultiline(multiline bool) {
+	tp.Multiline = multiline
+}
 
-	return nil
+func (tp *tagProperties) setFromFile(fromFile bool) {
+	tp.FromFile = fromFile
 }
 
-func parseTagAndTagValues(tag string) tagProperties {
-	properties := splitTagRespectingQuotes(tag)
-	tagProp := tagProperties{}
-	envName := properties[0]
-	tagProp.setEnvName(envName)
-	// setting defaults
-	tagProp.setDefaultValue("")
-	tagProp.setRequired(false)
-	tagProp.setDelimiter(",")
-	if len(properties) > 1 {
-		for _, prop := range properties[1:] {
-			// the required field in prop is of type "required" or "required=true"
-			checkAndSetTagPropRequired(prop, &tagProp)
-			checkAndSetTagPropDefaultValue(prop, &tagProp)
-			checkAndSetTagPropDelimiterForSliceOrArray(prop, &tagProp)
-			cehckAndSetIsStringForByteOrRuneArray(prop, &tagProp)
-		}
-	}
+func (tp *tagProperties) setUnit(unit time.Duration) {
+	tp.Unit = unit
+}
 
-	return tagProp
+func (tp *tagProperties) setUniqueMapKeys(uniqueMapKeys bool) {
+	tp.uniqueMapKeys = uniqueMapKeys
 }
 
-func setEnvVarValues(fieldValue reflect.Value, tagProp tagProperties, envValue string) error {
-	switch fieldValue.Kind() {
-	case reflect.String:
-		// set the field value to the env var value
-		fieldValue.SetString(envValue)
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		intValue, err := strconv.ParseInt(envValue, 10, 64)
-		if err != nil {
-			return fmt.Errorf("failed to convert %s to int: %w", tagProp.EnvName, err)
-		}
-		fieldValue.SetInt(intValue)
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		uintValue, err := strconv.ParseUint(envValue, 10, 64)
-		if err != nil {
-			return fmt.Errorf("failed to convert %s to uint: %w", tagProp.EnvName, err)
-		}
-		fieldValue.SetUint(uintValue)
-	case reflect.Float32, reflect.Float64:
-		floatValue, err := strconv.ParseFloat(envValue, 64)
-		if err != nil {
-			return fmt.Errorf("failed to convert %s to float: %w", tagProp.EnvName, err)
-		}
-		fieldValue.SetFloat(floatValue)
-	case reflect.Complex64, reflect.Complex128:
-		envValue = strings.ReplaceAll(envValue, " ", "")
-		complexValue, err := strconv.ParseComplex(envValue, 128)
-		if err != nil {
-			return fmt.Errorf("failed to convert %s to complex: %w", tagProp.EnvName, err)
-		}
-		fieldValue.SetComplex(complexValue)
-	case reflect.Slice, reflect.Array:
-		if err := setEnvVarSliceOrArrayValues(fieldValue, tagProp.EnvName, envValue, tagProp); err != nil {
-			return err
-		}
-	case reflect.Map:
-		if err := setEnvVarMapValues(fieldValue, tagProp.EnvName, envValue, tagProp); err != nil {
-			return err
-		}
-	case reflect.Bool:
-		// set the field value to the env var value
-		boolValue, err := strconv.ParseBool(envValue)
-		if err != nil {
-			return fmt.Errorf("error parsing env var %s: %w", tagProp.EnvName, err)
-		}
-		fieldValue.SetBool(boolValue)
-	case reflect.Interface:
-		// set the field value to the env var value
-		fieldValue.Set(reflect.ValueOf(envValue))
-	default:
-		return fmt.Errorf("unsupported field type: %s", fieldValue.Kind())
-	}
-	return nil
+func (tp *tagProperties) setJSONMapFallback(jsonMapFallback bool) {
+	tp.jsonMapFallback = jsonMapFallback
 }
 
-func setEnvVarSliceOrArrayValues(fieldValue reflect.Value, envName string, envValue string, tagProp tagProperties) error {
-	envValSliceOrArray := strings.Split(envValue, tagProp.Delimiter)
-	isString := tagProp.isString
+func (tp *tagProperties) setAllowShortArray(allowShortArray bool) {
+	tp.allowShortArray = allowShortArray
+}
 
-	// Determine the type: slice or array
-	kind := fieldValue.Kind()
-	elemType := fieldValue.Type().Elem()
+func (tp *tagProperties) setTruncateArray(truncateArray bool) {
+	tp.truncateArray = truncateArray
+}
 
-	// Create new slice or get a new array instance
-	var newValue reflect.Value
-	switch kind {
-	case reflect.Slice:
-		newValue = reflect.MakeSlice(fieldValue.Type(), len(envValSliceOrArray), len(envValSliceOrArray))
-	case reflect.Array:
-		if len(envValSliceOrArray) != fieldValue.Len() {
-			return fmt.Errorf("env var %s has %d values, but array expects %d", envName, len(envValSliceOrArray), fieldValue.Len())
-		}
-		newValue = fieldValue
-	}
+func (tp *tagProperties) setIgnoreTrailingDelim(ignoreTrailingDelim bool) {
+	tp.ignoreTrailingDelim = ignoreTrailingDelim
+}
 
-	// Set elements
-	for i, v := range envValSliceOrArray {
-		strVal := strings.TrimSpace(v)
+func (tp *tagProperties) setEmptyAsNil(emptyAsNil bool) {
+	tp.emptyAsNil = emptyAsNil
+}
 
-		switch elemType.Kind() {
-		case reflect.String:
-			newValue.Index(i).SetString(strVal)
+func (tp *tagProperties) setOptional(optional bool) {
+	tp.optional = optional
+}
 
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			if isString && elemType.Kind() == reflect.Int32 {
-				fieldValue.Set(reflect.ValueOf([]rune(envValue)))
-				return nil
-			}
-			intValue, err := strconv.ParseInt(strVal, 10, elemType.Bits())
-			if err != nil {
-				return fmt.Errorf("failed to convert %s to int: %w", envName, err)
-			}
-			newValue.Index(i).SetInt(intValue)
+func (tp *tagProperties) setDefaultSet(defaultSet bool) {
+	tp.defaultSet = defaultSet
+}
 
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			if isString && elemType.Kind() == reflect.Uint8 {
-				fieldValue.SetBytes([]byte(envValue))
-				return nil
-			}
-			uintValue, err := strconv.ParseUint(strVal, 10, elemType.Bits())
-			if err != nil {
-				return fmt.Errorf("failed to convert %s to uint: %w", envName, err)
-			}
-			newValue.Index(i).SetUint(uintValue)
+func (tp *tagProperties) setUnset(unset bool) {
+	tp.unset = unset
+}
 
-		case reflect.Float32, reflect.Float64:
-			floatValue, err := strconv.ParseFloat(strVal, elemType.Bits())
-			if err != nil {
-				return fmt.Errorf("failed to convert %s to float: %w", envName, err)
-			}
-			newValue.Index(i).SetFloat(floatValue)
+func (tp *tagProperties) setTrimQuotes(trimQuotes bool) {
+	tp.trimQuotes = trimQuotes
+}
 
-		case reflect.Complex64, reflect.Complex128:
-			complexValue, err := strconv.ParseComplex(strVal, elemType.Bits())
-			if err != nil {
-				return fmt.Errorf("failed to convert %s to complex: %w", envName, err)
-			}
-			newValue.Index(i).SetComplex(complexValue)
+func (tp *tagProperties) setIntBools(intBools bool) {
+	tp.intBools = intBools
+}
 
-		case reflect.Bool:
-			boolValue, err := strconv.ParseBool(strVal)
-			if err != nil {
-				return fmt.Errorf("error parsing env var %s: %w", envName, err)
-			}
-			newValue.Index(i).SetBool(boolValue)
+func (tp *tagProperties) setBoolWords(boolWords bool) {
+	tp.boolWords = boolWords
+}
 
-		case reflect.Interface:
-			newValue.Index(i).Set(reflect.ValueOf(strVal))
-		default:
-			return fmt.Errorf("unsupported slice/array element type: %s", elemType.Kind())
-		}
+func (tp *tagProperties) setPattern(pattern string, regex *regexp.Regexp) {
+	tp.Pattern = pattern
+	tp.regex = regex
+}
+
+/*
+Parse the env var from the config struct
+*/
+func parseEnvVar[T any](config *T, settings *settings) error {
+	// get the value of the config
+	value := reflect.ValueOf(config)
+
+	// check if config is a pointer to a struct
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return ErrConfigNotPtrToStruct
 	}
 
-	// Set the final value
-	fieldValue.Set(newValue)
-	return nil
+	// the errors of all the fields and checks are joined with the join field errors setting
+	if settings.joinFieldErrors {
+		return errors.Join(parseStructFields(value.Elem(), settings), setFlagValues(settings), checkEnvGroups(settings), checkRequiredIfs(settings))
+	}
+	if err := parseStructFields(value.Elem(), settings); err != nil {
+		return err
+	}
+	// override the fields with the command-line flags explicitly set, before the checks using their values
+	if err := setFlagValues(settings); err != nil {
+		return err
+	}
+	if err := checkEnvGroups(settings); err != nil {
+		return err
+	}
+	return checkRequiredIfs(settings)
 }
 
-func setEnvVarMapValues(fieldValue reflect.Value, envName string, envValue string, tagProp tagProperties) error {
-	// set the field value to the env var value
-	mapValues := strings.Split(envValue, tagProp.Delimiter)
-	lenMapValues := len(mapValues)
-	//replace starting braces and ending braces
-	mapValues[0] = strings.ReplaceAll(mapValues[0], "{", "")
-	mapValues[lenMapValues-1] = strings.ReplaceAll(mapValues[lenMapValues-1], "}", "")
-	newMap := reflect.MakeMapWithSize(fieldValue.Type(), lenMapValues)
+/*
+Parse the env vars into the fields of the struct value, recursing into embedded structs
+*/
+func parseStructFields(value reflect.Value, settings *settings) error {
+	// get the type of the config and its parsed tags
+	typ := value.Type()
+	tags := structTagProperties(typ, settings.TagName)
 
-	for _, pair := range mapValues {
-		keyValue := strings.SplitN(pair, ":", 2)
-		if len(keyValue) != 2 {
-			return fmt.Errorf("invalid map entry for %s: %s", envName, pair)
+	// loop through the fields of the struct, the field errors are joined with the join field errors setting
+	var fieldErrors []error
+	for i := range typ.NumField() {
+		if err := parseStructField(value, typ.Field(i), i, tags, settings); err != nil {
+			if !settings.joinFieldErrors {
+				return err
+			}
+			fieldErrors = append(fieldErrors, err)
 		}
+	}
 
-		key := strings.TrimSpace(keyValue[0])
-		value := strings.TrimSpace(keyValue[1])
+	return errors.Join(fieldErrors...)
+}
 
-		mapKey := reflect.New(fieldValue.Type().Key()).Elem()
-		mapValue := reflect.New(fieldValue.Type().Elem()).Elem()
+/*
+Parse the env var of the struct field at index i into the field of the struct value
+*/
+func parseStructField(value reflect.Value, field reflect.StructField, i int, tags []parsedTag, settings *settings) error {
+	typ := value.Type()
+	tagValues, hasTag := field.Tag.Lookup(settings.TagName) // get the tag value
 
-		// Set key
-		switch mapKey.Kind() {
-		case reflect.String:
-			mapKey.SetString(key)
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			intKey, err := strconv.ParseInt(key, 10, mapKey.Type().Bits())
-			if err != nil {
-				return fmt.Errorf("failed to convert map key %s to int: %w", key, err)
-			}
-			mapKey.SetInt(intKey)
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			uintKey, err := strconv.ParseUint(key, 10, mapKey.Type().Bits())
-			if err != nil {
-				return fmt.Errorf("failed to convert map key %s to uint: %w", key, err)
-			}
-			mapKey.SetUint(uintKey)
-		case reflect.Float32, reflect.Float64:
-			floatKey, err := strconv.ParseFloat(key, mapKey.Type().Bits())
-			if err != nil {
-				return fmt.Errorf("failed to convert map key %s to float: %w", key, err)
-			}
-			mapKey.SetFloat(floatKey)
-		case reflect.Complex64, reflect.Complex128:
-			complexKey, err := strconv.ParseComplex(key, mapKey.Type().Bits())
-			if err != nil {
-				return fmt.Errorf("failed to convert map key %s to complex: %w", key, err)
-			}
-			mapKey.SetComplex(complexKey)
-		case reflect.Bool:
-			boolKey, err := strconv.ParseBool(key)
-			if err != nil {
-				return fmt.Errorf("failed to convert map key %s to bool: %w", key, err)
+	// promote the fields of untagged embedded structs into the parent, allocating the nil struct pointers
+	if tagValues == "" && isEmbeddedStruct(field) {
+		fieldValue := value.Field(i)
+		if fieldValue.Kind() == reflect.Pointer {
+			if fieldValue.IsNil() {
+				if !fieldValue.CanSet() {
+					return fmt.Errorf("cannot set unexported field %s", field.Name)
+				}
+				fieldValue.Set(reflect.New(field.Type.Elem()))
 			}
-			mapKey.SetBool(boolKey)
-		case reflect.Interface:
-			mapKey.Set(reflect.ValueOf(key))
-		default:
-			return fmt.Errorf("unsupported map key type: %s", mapKey.Kind())
+			fieldValue = fieldValue.Elem()
 		}
+		return parseEmbeddedStructFields(fieldValue, field.Name, settings)
+	}
 
-		// Set value
-		switch mapValue.Kind() {
-		case reflect.String:
-			mapValue.SetString(value)
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			intValue, err := strconv.ParseInt(value, 10, mapValue.Type().Bits())
-			if err != nil {
-				return fmt.Errorf("failed to convert map value %s to int: %w", value, err)
-			}
-			mapValue.SetInt(intValue)
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			uintValue, err := strconv.ParseUint(value, 10, mapValue.Type().Bits())
-			if err != nil {
-				return fmt.Errorf("failed to convert map value %s to uint: %w", value, err)
-			}
-			mapValue.SetUint(uintValue)
-		case reflect.Float32, reflect.Float64:
-			floatValue, err := strconv.ParseFloat(value, mapValue.Type().Bits())
-			if err != nil {
-				return fmt.Errorf("failed to convert map value %s to float: %w", value, err)
-			}
-			mapValue.SetFloat(floatValue)
-		case reflect.Bool:
-			boolValue, err := strconv.ParseBool(value)
-			if err != nil {
-				return fmt.Errorf("failed to convert map value %s to bool: %w", value, err)
-			}
-			mapValue.SetBool(boolValue)
-		case reflect.Complex64, reflect.Complex128:
-			complexValue, err := strconv.ParseComplex(value, mapValue.Type().Bits())
-			if err != nil {
-				return fmt.Errorf("failed to convert map value %s to complex: %w", value, err)
-			}
-			mapValue.SetComplex(complexValue)
-		case reflect.Interface:
-			mapValue.Set(reflect.ValueOf(value))
-		default:
-			return fmt.Errorf("unsupported map value type: %s", mapValue.Kind())
-		}
+	// skip the fields filtered out of a partial load
+	if settings.fieldFilter != nil && !settings.fieldFilter[field.Name] {
+		return nil
+	}
 
-		newMap.SetMapIndex(mapKey, mapValue)
+	// check if the tag is missing
+	if !hasTag {
+		return ErrTagNotFound
 	}
 
-	fieldValue.Set(newMap)
-	return nil
-}
+	// skip the fields explicitly ignored with `env:"-"`
+	if tagValues == "-" {
+		return nil
+	}
 
-func checkAndSetTagPropRequired(property string, tagProp *tagProperties) {
-	if !strings.Contains(strings.ToLower(property), "required") {
-		return
+	// unexported fields can not be set through reflection
+	if !value.Field(i).CanSet() {
+		return fmt.Errorf("cannot set unexported field %s", field.Name)
 	}
-	// check if the required field is set to true or false
-	if strings.Contains(property, "=") {
-		property = strings.Split(property, "=")[1]
-		property = strings.TrimSpace(property)
-		property = strings.ToLower(property)
+
+	// get the field value
+	tagProp, err := tags[i].tagProp, tags[i].err
+	if err != nil {
+		return err
 	}
-	if strings.Contains(property, "true") {
-		tagProp.setRequired(true)
-	} else if strings.Contains(property, "false") {
-		tagProp.setRequired(false)
-	} else {
-		tagProp.setRequired(true)
+	// a tagged embedded struct is only loaded from json
+	if isEmbeddedStruct(field) && !tagProp.JSON {
+		return embeddedStructTagError(field)
+	}
+	// derive the env var name from the field name when the tag has none
+	if tagProp.EnvName == "" {
+		tagProp.setEnvName(settings.NameStrategy(field.Name))
 	}
 
-}
-
-func checkAndSetTagPropDefaultValue(property string, tagProp *tagProperties) {
-	if !strings.Contains(strings.ToLower(property), "default") {
-		return
+	// prefix the env names of the indexed struct slice elements (e.g. SERVERS_0_)
+	if settings.envPrefix != "" {
+		tagProp = prefixTagProperties(tagProp, settings.envPrefix)
 	}
-	if !strings.Contains(property, "=") {
-		return
+	// every field without a default is required with the required all option, unless it opts out
+	if settings.RequiredAll && !tagProp.optional && !tagProp.defaultSet {
+		tagProp.setRequired(true)
 	}
-	property = strings.SplitN(property, "=", 2)[1]
-	property = strings.TrimSpace(property)
-	property = strings.ToLower(property)
-	valLen := len(property)
-
-	if valLen >= 2 {
-		first, last := property[0], property[valLen-1]
-		if (first == last) && (first == '"' || first == '\'') {
-			property = strings.TrimSpace(property[1 : valLen-1])
+	// slices of structs are loaded from the indexed env vars unless the env var itself is set
+	if isIndexedStructSliceType(field.Type) && !tagProp.JSON {
+		_, exist, err := settings.lookupEnv(tagProp.EnvName)
+		if err != nil {
+			return err
+		}
+		if !exist {
+			if err := setIndexedStructSliceValues(value.Field(i), tagProp, settings); err != nil {
+				return settings.formatFieldError(typ, field.Name, tagProp.EnvName, err)
+			}
+			return nil
 		}
 	}
-	tagProp.setDefaultValue(property)
-}
 
-func checkAndSetTagPropDelimiterForSliceOrArray(property string, tagProp *tagProperties) {
-	if !strings.Contains(strings.ToLower(property), "delimiter") {
-		return
+	// track the source env vars for the cache invalidation
+	settings.envSources = append(settings.envSources, envSource{name: tagProp.EnvName, versioned: tagProp.Versioned})
+	for _, alias := range slices.Concat(tagProp.Aliases, tagProp.Deprecated) {
+		settings.envSources = append(settings.envSources, envSource{name: alias, versioned: tagProp.Versioned})
 	}
-	if !strings.Contains(property, "=") {
-		return
+	if tagProp.FromFile || settings.FileFallback {
+		settings.envSources = append(settings.envSources, envSource{name: tagProp.EnvName + "_FILE"})
 	}
-	property = strings.SplitN(property, "=", 2)[1]
-	property = strings.TrimSpace(property)
-	property = strings.ToLower(property)
-	valLen := len(property)
 
-	if valLen >= 2 {
-		first, last := property[0], property[valLen-1]
-		if (first == last) && (first == '"' || first == '\'') {
-			property = strings.TrimSpace(property[1 : valLen-1])
-			tagProp.setDelimiter(property)
+	// use the default of the active environment if any
+	if defaultValue, ok := tagProp.EnvironmentDefaults[strings.ToLower(settings.Environment)]; ok {
+		tagProp.setDefaultValue(defaultValue)
+		tagProp.setDefaultSet(true)
+	}
+	// pick the tag default or the shared default by precedence
+	defaultValue, defaultSource := settings.fieldDefault(tagProp.DefaultValue, tagProp.EnvName)
+	tagProp.setDefaultValue(defaultValue)
+	// an empty tag default (e.g. default=) is a real default with the allow empty default option
+	hasDefault := defaultValue != ""
+	if !hasDefault && settings.AllowEmptyDefault && tagProp.defaultSet && settings.sourceRank(SourceTagDefault) >= 0 {
+		hasDefault, defaultSource = true, SourceTagDefault
+	}
+
+	// maps are collected from the env vars matching the glob env name, e.g. FEATURE_*
+	if isGlobEnvName(tagProp.EnvName) {
+		if err := setGlobMapValues(value.Field(i), tagProp, settings); err != nil {
+			return settings.formatFieldError(typ, field.Name, tagProp.EnvName, err)
 		}
+		return nil
 	}
-}
 
-func cehckAndSetIsStringForByteOrRuneArray(property string, tagProp *tagProperties) {
-	if !strings.Contains(strings.ToLower(property), "isstring") {
-		return
+	//get and set the env var value
+	if err := settings.ctx.Err(); err != nil {
+		return err
 	}
-	// check if the required field is set to true or false
-	if strings.Contains(property, "=") {
-		property = strings.Split(property, "=")[1]
-		property = strings.TrimSpace(property)
-		property = strings.ToLower(property)
+	envValue, foundName, source, exist, err := lookupFieldEnv(tagProp, settings)
+	if err != nil {
+		return err
+	}
+	// read the value from the file named by the NAME_FILE env var or else by the value itself,
+	// with the file fallback the NAME_FILE env var is only used when the env var is not set
+	if tagProp.FromFile || (settings.FileFallback && !exist) {
+		envValue, foundName, source, exist, err = lookupFileEnv(tagProp, envValue, foundName, source, exist, settings)
+		if err != nil {
+			return settings.formatFieldError(typ, field.Name, tagProp.EnvName, withFieldMessage(tagProp.Message, err))
+		}
+	}
+	// a default of a higher precedence than the source of the value wins over it
+	if exist && hasDefault && settings.sourceRank(defaultSource) < settings.sourceRank(source) {
+		exist = false
+	}
+	// track the set fields of the group for the group checks, a field set by its flag is set too
+	if tagProp.Group != "" {
+		settings.trackEnvGroup(tagProp.Group, tagProp.EnvName, exist || settings.isFlagSet(tagProp.EnvName))
+	}
+	// warn about the values still set under a deprecated name
+	if exist && settings.DeprecationLogger != nil && slices.Contains(tagProp.Deprecated, foundName) {
+		settings.DeprecationLogger(fmt.Sprintf("env var %s is deprecated; use %s", foundName, tagProp.EnvName))
+	}
+	if !exist {
+		// report the missing env var, e.g. for the metrics of the defaults in use
+		if settings.OnMissing != nil {
+			settings.OnMissing(tagProp.EnvName, hasDefault)
+		}
+		// check if the field is required
+		if tagProp.Required && !hasDefault && !settings.isFlagSet(tagProp.EnvName) {
+			return settings.formatFieldError(typ, field.Name, tagProp.EnvName, withFieldMessage(tagProp.Message, fmt.Errorf("required environment variable %s not found", tagProp.EnvName)))
+		}
+		// set the field value to the default value, expanding its ${VAR} references with the expand defaults option
+		envValue = tagProp.DefaultValue
+		if settings.ExpandDefaults {
+			envValue, err = expandDefaultValue(envValue, settings)
+			if err != nil {
+				return settings.formatFieldError(typ, field.Name, tagProp.EnvName, fmt.Errorf("failed to expand default value of %s: %w", tagProp.EnvName, err))
+			}
+		}
+	}
+	// track the unset conditionally required fields and the resolved values for the requiredif checks
+	if tagProp.RequiredIf != "" && !exist && !hasDefault && !settings.isFlagSet(tagProp.EnvName) {
+		settings.trackRequiredIf(requiredIfCheck{tagProp.EnvName, tagProp.RequiredIf, tagProp.RequiredIfValue, tagProp.Message})
+	}
+	settings.trackFieldValue(tagProp.EnvName, envValue)
+	if !exist {
+		source = defaultSource
+	}
+	settings.trackFieldSource(tagProp.EnvName, source)
+	settings.logFieldValue(typ, field.Name, tagProp, foundName, source, envValue)
+	// expand the env var references of the string values, the defaults are expanded with the expand defaults option
+	if settings.ExpandVars && exist {
+		tagProp.setExpandVars(func(value string) (string, error) {
+			return expandEnvRefs(value, settings)
+		})
+	}
+	tagProp.setUniqueMapKeys(settings.ErrorOnDuplicateMapKeys)
+	tagProp.setJSONMapFallback(settings.JSONMapFallback)
+	tagProp.setAllowShortArray(settings.AllowShortArray)
+	tagProp.setTruncateArray(settings.TruncateArray)
+	tagProp.setIgnoreTrailingDelim(settings.IgnoreTrailingDelimiter)
+	tagProp.setEmptyAsNil(settings.EmptyAsNil)
+	tagProp.setTrimQuotes(settings.TrimQuotes)
+	tagProp.setUnset(!exist && !hasDefault)
+	tagProp.setBoolWords(settings.Lenient)
+	tagProp.setIntBools(settings.LenientBool)
+	// set the field value
+	fieldValue := value.Field(i)
+	if settings.Lenient && !tagProp.isString {
+		envValue = coerceLenientValue(fieldValue.Kind(), envValue)
+	}
+	// the fields with a flag set are converted from the flag value by the flag override, not the env value
+	if settings.isFlagSet(tagProp.EnvName) {
+		settings.trackFlagField(typ, field.Name, fieldValue, tagProp)
+		return nil
+	}
+	// transform the raw value of the field before the conversion, e.g. strings.ToLower
+	if transform, ok := settings.fieldTransform(typ, field.Name); ok && !tagProp.unset {
+		envValue = transform(envValue)
+	}
+	// a registered converter for the field takes precedence over the type
+	if converter, ok := lookupFieldConverter(typ, field.Name); ok {
+		if err := setFieldConverterValue(fieldValue, tagProp, envValue, converter); err != nil {
+			return settings.formatFieldError(typ, field.Name, tagProp.EnvName, withFieldMessage(tagProp.Message, err))
+		}
+		return nil
+	}
+	// the allowed empty defaults leave the slices, arrays and maps empty
+	if hasDefault && !exist && defaultValue == "" && !isScalarValue(fieldValue) {
+		fieldValue.SetZero()
+		return nil
+	}
+	// required slices, arrays and maps must have at least one value
+	requiredCollection := tagProp.Required && !isScalarValue(fieldValue)
+	if requiredCollection && strings.TrimSpace(envValue) == "" {
+		return settings.formatFieldError(typ, field.Name, tagProp.EnvName, withFieldMessage(tagProp.Message, fmt.Errorf("required environment variable %s is empty", tagProp.EnvName)))
+	}
+	if err := setEnvVarValues(fieldValue, tagProp, envValue); err != nil {
+		return settings.formatFieldError(typ, field.Name, tagProp.EnvName, withFieldMessage(tagProp.Message, err))
+	}
+	if requiredCollection && fieldValue.Kind() != reflect.Array && fieldValue.Len() == 0 {
+		return settings.formatFieldError(typ, field.Name, tagProp.EnvName, withFieldMessage(tagProp.Message, fmt.Errorf("required environment variable %s is empty", tagProp.EnvName)))
+	}
+
+	return nil
+}
+
+// parsedTag is the parsed env tag of a struct field
+type parsedTag struct {
+	tagProp tagProperties
+	err     error
+}
+
+// parsedTagsKey is the key of the parsed tags, a struct type can be loaded with several tag names
+type parsedTagsKey struct {
+	typ     reflect.Type
+	tagName string
+}
+
+/*
+Get the parsed env tags of the struct fields by field index, the tags are parsed
+once per struct type and tag name and reused by the later loads
+*/
+func structTagProperties(typ reflect.Type, tagName string) []parsedTag {
+	key := parsedTagsKey{typ, tagName}
+	if cached, ok := parsedTags.Load(key); ok {
+		return cached.([]parsedTag)
+	}
+	tags := make([]parsedTag, typ.NumField())
+	for i := range typ.NumField() {
+		tagValues, hasTag := typ.Field(i).Tag.Lookup(tagName)
+		if !hasTag || tagValues == "-" {
+			continue
+		}
+		tags[i].tagProp, tags[i].err = parseTagAndTagValues(tagValues)
+	}
+	parsedTags.Store(key, tags)
+	return tags
+}
+
+// isEmbeddedStruct reports whether the field is an embedded struct or struct pointer
+func isEmbeddedStruct(field reflect.StructField) bool {
+	typ := field.Type
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return field.Anonymous && typ.Kind() == reflect.Struct
+}
+
+// embeddedStructTagError is the error of a tagged embedded struct, naming the field
+func embeddedStructTagError(field reflect.StructField) error {
+	return fmt.Errorf("%w: remove the tag of %s to promote its fields or add the json option", ErrEmbeddedStructTag, field.Name)
+}
+
+/*
+Parse the env vars into the embedded struct, all of its fields are loaded when
+the embedded struct itself is named in the field filter
+*/
+func parseEmbeddedStructFields(value reflect.Value, name string, settings *settings) error {
+	fieldFilter := settings.fieldFilter
+	if fieldFilter[name] {
+		settings.fieldFilter = nil
+		defer func() { settings.fieldFilter = fieldFilter }()
+	}
+	return parseStructFields(value, settings)
+}
+
+/*
+Lookup the env var of the field, the fallback names and then the deprecated names are tried in order
+when the env var is not set, returns the name and the source the value was found in
+*/
+func lookupFieldEnv(tagProp tagProperties, settings *settings) (string, string, ValueSource, bool, error) {
+	for _, envName := range slices.Concat([]string{tagProp.EnvName}, tagProp.Aliases, tagProp.Deprecated) {
+		envValue, source, exist, err := settings.lookupEnvSource(envName)
+		if tagProp.Versioned {
+			envValue, source, exist, err = lookupVersionedEnv(envName, settings)
+		}
+		if err != nil || exist {
+			return envValue, envName, source, exist, err
+		}
+	}
+	return "", tagProp.EnvName, 0, false, nil
+}
+
+/*
+Lookup the file of the field, the path is the value of the companion NAME_FILE env var (e.g. the
+secrets mounted by Kubernetes) or else the value of the env var, the trailing newline of the file is trimmed
+*/
+func lookupFileEnv(tagProp tagProperties, envValue string, foundName string, source ValueSource, exist bool, settings *settings) (string, string, ValueSource, bool, error) {
+	fileName := tagProp.EnvName + "_FILE"
+	path, fileSource, fileExist, err := settings.lookupEnvSource(fileName)
+	if err != nil {
+		return "", foundName, source, false, err
+	}
+	switch {
+	case fileExist:
+		foundName, source = fileName, fileSource
+	case exist:
+		path = envValue
+	default:
+		return envValue, foundName, source, false, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", foundName, source, false, fmt.Errorf("failed to read file of %s: %w", foundName, err)
+	}
+	value := strings.TrimSuffix(strings.TrimSuffix(string(content), "\n"), "\r")
+	return value, foundName, source, true, nil
+}
+
+/*
+Lookup the highest version of a rotated env var, versions are named NAME_V<n>
+(e.g. API_KEY_V2, API_KEY_V3) and the unversioned NAME is used as a fallback
+*/
+func lookupVersionedEnv(envName string, settings *settings) (string, ValueSource, bool, error) {
+	prefix := envName + "_V"
+	latestVersion := -1
+	latestName := ""
+	names, err := settings.envNames()
+	if err != nil {
+		return "", 0, false, err
+	}
+	for _, name := range names {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		version, err := strconv.Atoi(name[len(prefix):])
+		if err != nil || version < 0 {
+			continue
+		}
+		if version > latestVersion {
+			latestVersion = version
+			latestName = name
+		}
+	}
+	if latestVersion >= 0 {
+		return settings.lookupEnvSource(latestName)
+	}
+	return settings.lookupEnvSource(envName)
+}
+
+/*
+Coerce the common mismatches of the env var value for the lenient mode
+*/
+func coerceLenientValue(kind reflect.Kind, envValue string) string {
+	envValue = strings.TrimSpace(envValue)
+	if unquoted, ok := trimMatchingQuotes(envValue); ok {
+		envValue = strings.TrimSpace(unquoted)
+	}
+
+	switch kind {
+	case reflect.Bool:
+		return normalizeBoolWord(envValue)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		if envValue == "" {
+			return "0"
+		}
+	}
+	return envValue
+}
+
+// normalizeBoolWord maps the bool words (on/off, yes/no, y/n) to true and false
+func normalizeBoolWord(value string) string {
+	switch strings.ToLower(value) {
+	case "on", "yes", "y":
+		return "true"
+	case "off", "no", "n":
+		return "false"
+	}
+	return value
+}
+
+// parseBool parses the bool value of the scalars, slices and maps, accepting the bool words when enabled
+// and any integer when int bools are enabled (nonzero is true)
+func parseBool(value string, boolWords bool, intBools bool) (bool, error) {
+	if boolWords {
+		value = normalizeBoolWord(strings.TrimSpace(value))
+	}
+	boolValue, err := strconv.ParseBool(value)
+	if err != nil && intBools {
+		if intValue, intErr := strconv.ParseInt(strings.TrimSpace(value), 10, 64); intErr == nil {
+			return intValue != 0, nil
+		}
+	}
+	return boolValue, err
+}
+
+func parseTagAndTagValues(tag string) (tagProperties, error) {
+	properties := splitTagRespectingQuotes(tag)
+	tagProp := tagProperties{}
+	if len(properties) == 0 {
+		properties = []string{""}
+	}
+	// the fallback names of the env var are separated by "|", e.g. NEW_NAME|OLD_NAME
+	envNames := strings.Split(properties[0], "|")
+	if len(envNames) > 1 {
+		envNames[0] = strings.TrimSpace(envNames[0])
+	}
+	tagProp.setEnvName(envNames[0])
+	for _, alias := range envNames[1:] {
+		if alias = strings.TrimSpace(alias); alias != "" {
+			tagProp.setAliases(append(tagProp.Aliases, alias))
+		}
+	}
+	// setting defaults
+	tagProp.setDefaultValue("")
+	tagProp.setRequired(false)
+	tagProp.setDelimiter(",")
+	tagProp.setValueDelimiter("|")
+	tagProp.setBase(10)
+	for _, prop := range properties[1:] {
+		// the values of the properties may contain any of the other keywords, so only the key is matched
+		switch key := tagPropertyKey(prop); key {
+		case "":
+			// an empty property, e.g. a trailing comma
+		case "required":
+			if err := checkAndSetTagPropRequired(prop, &tagProp); err != nil {
+				return tagProp, err
+			}
+		case "requiredif":
+			if err := checkAndSetTagPropRequiredIf(prop, &tagProp); err != nil {
+				return tagProp, err
+			}
+		case "default":
+			checkAndSetTagPropDefaultValue(prop, &tagProp)
+		case "delimiter":
+			checkAndSetTagPropDelimiterForSliceOrArray(prop, &tagProp)
+		case "valuedelimiter":
+			checkAndSetTagPropValueDelimiter(prop, &tagProp)
+		case "isstring":
+			cehckAndSetIsStringForByteOrRuneArray(prop, &tagProp)
+		case "unique":
+			checkAndSetTagPropUnique(prop, &tagProp)
+		case "versioned":
+			checkAndSetTagPropVersioned(prop, &tagProp)
+		case "json":
+			checkAndSetTagPropJSON(prop, &tagProp)
+		case "bytesize":
+			checkAndSetTagPropByteSize(prop, &tagProp)
+		case "percent":
+			checkAndSetTagPropPercent(prop, &tagProp)
+		case "notrim":
+			checkAndSetTagPropNoTrim(prop, &tagProp)
+		case "secret":
+			checkAndSetTagPropSecret(prop, &tagProp)
+		case "infer":
+			checkAndSetTagPropInfer(prop, &tagProp)
+		case "multiline":
+			checkAndSetTagPropMultiline(prop, &tagProp)
+		case "fromfile":
+			checkAndSetTagPropFromFile(prop, &tagProp)
+		case "hex":
+			checkAndSetTagPropHex(prop, &tagProp)
+		case "sort":
+			if err := checkAndSetTagPropSortOrder(prop, &tagProp); err != nil {
+				return tagProp, err
+			}
+		case "encoding":
+			if err := checkAndSetTagPropEncoding(prop, &tagProp); err != nil {
+				return tagProp, err
+			}
+		case "base":
+			if err := checkAndSetTagPropBase(prop, &tagProp); err != nil {
+				return tagProp, err
+			}
+		case "unit":
+			if err := checkAndSetTagPropUnit(prop, &tagProp); err != nil {
+				return tagProp, err
+			}
+		case "msg":
+			checkAndSetTagPropMessage(prop, &tagProp)
+		case "layout":
+			checkAndSetTagPropLayout(prop, &tagProp)
+		case "group":
+			checkAndSetTagPropGroup(prop, &tagProp)
+		case "deprecated":
+			checkAndSetTagPropDeprecated(prop, &tagProp)
+		case "forbid":
+			checkAndSetTagPropForbidden(prop, &tagProp)
+		case "regex", "pattern":
+			if err := checkAndSetTagPropPattern(prop, &tagProp); err != nil {
+				return tagProp, err
+			}
+		default:
+			// environment defaults are keyed by their environment, e.g. default.production=value
+			if isEnvironmentDefaultProperty(prop) {
+				checkAndSetTagPropEnvironmentDefault(prop, &tagProp)
+				continue
+			}
+			return tagProp, fmt.Errorf("unknown tag option %s for %s", key, tagProp.EnvName)
+		}
+	}
+
+	return tagProp, nil
+}
+
+func setEnvVarValues(fieldValue reflect.Value, tagProp tagProperties, envValue string) error {
+	// strip the quotes wrapping the scalar values, the quotes of list values are kept for splitting
+	if tagProp.trimQuotes && isScalarValue(fieldValue) {
+		envValue, _ = trimMatchingQuotes(envValue)
+	}
+
+	// special case for timezones
+	if fieldValue.Type() == locationType {
+		location, err := time.LoadLocation(envValue)
+		if err != nil {
+			return fmt.Errorf("failed to load timezone %s: %w", tagProp.EnvName, err)
+		}
+		fieldValue.Set(reflect.ValueOf(location))
+		return nil
+	}
+
+	// decode the encoded env var value
+	if len(tagProp.Encoding) > 0 && envValue != "" {
+		decoded, err := decodeEnvValue(tagProp.EnvName, envValue, tagProp.Encoding)
+		if err != nil {
+			return err
+		}
+		// byte slices get the raw decoded bytes
+		if fieldValue.Kind() == reflect.Slice && fieldValue.Type().Elem().Kind() == reflect.Uint8 {
+			fieldValue.SetBytes(decoded)
+			return nil
+		}
+		// byte arrays (e.g. [32]byte keys) must get exactly their length
+		if fieldValue.Kind() == reflect.Array && fieldValue.Type().Elem().Kind() == reflect.Uint8 {
+			if len(decoded) != fieldValue.Len() {
+				return fmt.Errorf("%s expects %d bytes, got %d", tagProp.EnvName, fieldValue.Len(), len(decoded))
+			}
+			reflect.Copy(fieldValue, reflect.ValueOf(decoded))
+			return nil
+		}
+		envValue = string(decoded)
+	}
+
+	// parse the env var value as json
+	if tagProp.JSON && envValue != "" {
+		return setEnvVarJSONValues(fieldValue, tagProp.EnvName, envValue)
+	}
+
+	// the database/sql null types (e.g. sql.NullString) are valid when the env var is set
+	if isSQLNullType(fieldValue.Type()) {
+		return setSQLNullValue(fieldValue, tagProp, envValue)
+	}
+
+	// types unmarshaling themselves from text (e.g. netip.Addr, time.Time) are converted as scalars
+	if _, ok := textUnmarshaler(fieldValue); ok {
+		if err := convertScalar(fieldValue, envValue, tagProp); err != nil {
+			return fieldScalarError(err, tagProp.EnvName, "unsupported field type", fieldValue.Kind())
+		}
+		return nil
+	}
+
+	switch fieldValue.Kind() {
+	case reflect.String:
+		if tagProp.expandVars != nil {
+			expanded, err := tagProp.expandVars(envValue)
+			if err != nil {
+				return fmt.Errorf("failed to expand %s: %w", tagProp.EnvName, err)
+			}
+			envValue = expanded
+		}
+		// unescape the literal \n, \r, \t and \\ of the multiline values (e.g. PEM keys)
+		if tagProp.Multiline {
+			envValue = multilineReplacer.Replace(envValue)
+		}
+		// validate the env var value against the pattern if any
+		if tagProp.regex != nil && !tagProp.regex.MatchString(envValue) {
+			return fmt.Errorf("%s does not match pattern %s", tagProp.EnvName, tagProp.Pattern)
+		}
+		// reject the placeholder values left over from templates
+		for _, forbidden := range tagProp.Forbidden {
+			if strings.EqualFold(strings.TrimSpace(envValue), forbidden) {
+				return fmt.Errorf("%s has forbidden placeholder value %s", tagProp.EnvName, envValue)
+			}
+		}
+		// set the field value to the env var value
+		fieldValue.SetString(envValue)
+	case reflect.Slice, reflect.Array:
+		// empty values leave the slices nil with the empty as nil option
+		if envValue == "" && tagProp.emptyAsNil && fieldValue.Kind() == reflect.Slice {
+			fieldValue.SetZero()
+			return nil
+		}
+		// slices of pairs are parsed as ordered maps
+		if isPairSliceType(fieldValue.Type()) {
+			return setEnvVarPairValues(fieldValue, tagProp.EnvName, envValue, tagProp)
+		}
+		if err := setEnvVarSliceOrArrayValues(fieldValue, tagProp.EnvName, envValue, tagProp); err != nil {
+			return err
+		}
+	case reflect.Map:
+		// empty values leave the maps nil with the empty as nil option
+		if envValue == "" && tagProp.emptyAsNil {
+			fieldValue.SetZero()
+			return nil
+		}
+		if err := setEnvVarMapValues(fieldValue, tagProp.EnvName, envValue, tagProp); err != nil {
+			return err
+		}
+	default:
+		// the scalars are converted like the slice elements and the map values
+		if err := convertScalar(fieldValue, envValue, tagProp); err != nil {
+			return fieldScalarError(err, tagProp.EnvName, "unsupported field type", fieldValue.Kind())
+		}
+	}
+	return nil
+}
+
+// trimSliceElement trims the whitespace around the slice element, unless the notrim property keeps it
+func trimSliceElement(value string, noTrim bool) string {
+	if noTrim {
+		return value
+	}
+	return strings.TrimSpace(value)
+}
+
+// complexSpaces matches the spaces around the operators and inside the parentheses of complex values
+var complexSpaces = regexp.MustCompile(`\s*[+-]\s*|\(\s+|\s+\)`)
+
+/*
+Normalize the spaces of complex values, so "1 + 2i" parses like "1+2i", only the spaces around
+the value, the operators and the parentheses are removed so malformed values like "1 2i" are rejected
+*/
+func normalizeComplex(value string) string {
+	return complexSpaces.ReplaceAllStringFunc(strings.TrimSpace(value), strings.TrimSpace)
+}
+
+func setEnvVarSliceOrArrayValues(fieldValue reflect.Value, envName string, envValue string, tagProp tagProperties) error {
+	// strings are taken whole by the rune and byte slices
+	if tagProp.isString && fieldValue.Kind() == reflect.Slice {
+		switch fieldValue.Type().Elem().Kind() {
+		case reflect.Int32:
+			fieldValue.Set(reflect.ValueOf([]rune(envValue)).Convert(fieldValue.Type()))
+			return nil
+		case reflect.Uint8:
+			fieldValue.SetBytes([]byte(envValue))
+			return nil
+		}
+	}
+
+	envValSliceOrArray := splitValueRespectingQuotes(envValue, tagProp.Delimiter)
+	if tagProp.ignoreTrailingDelim {
+		envValSliceOrArray = dropTrailingEmptyToken(envValSliceOrArray)
+	}
+
+	// the common slice types are built without the per element reflection
+	typedValue, ok, err := typedSliceValues(fieldValue.Type(), envValSliceOrArray, envName, tagProp)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return setNormalizedSliceOrArrayValues(fieldValue, typedValue, envName, tagProp)
+	}
+
+	// Determine the type: slice or array
+	kind := fieldValue.Kind()
+	elemType := fieldValue.Type().Elem()
+
+	// Create new slice or get a new array instance
+	var newValue reflect.Value
+	switch kind {
+	case reflect.Slice:
+		newValue = reflect.MakeSlice(fieldValue.Type(), len(envValSliceOrArray), len(envValSliceOrArray))
+	case reflect.Array:
+		// short arrays are zero-filled with the allow short array option and
+		// the extra values are dropped with the truncate array option
+		shortArray := len(envValSliceOrArray) < fieldValue.Len() && tagProp.allowShortArray
+		longArray := len(envValSliceOrArray) > fieldValue.Len() && tagProp.truncateArray
+		if len(envValSliceOrArray) != fieldValue.Len() && !shortArray && !longArray {
+			return fmt.Errorf("env var %s has %d values, but array expects %d", envName, len(envValSliceOrArray), fieldValue.Len())
+		}
+		if longArray {
+			envValSliceOrArray = envValSliceOrArray[:fieldValue.Len()]
+		}
+		fieldValue.SetZero()
+		newValue = fieldValue
+	}
+
+	// Set elements
+	for i, v := range envValSliceOrArray {
+		strVal := trimSliceElement(v, tagProp.NoTrim)
+		if elemType.Kind() == reflect.String && tagProp.expandVars != nil {
+			expanded, err := tagProp.expandVars(strVal)
+			if err != nil {
+				return fmt.Errorf("failed to expand %s: %w", envName, err)
+			}
+			strVal = expanded
+		}
+		if err := convertScalar(newValue.Index(i), strVal, tagProp); err != nil {
+			return fieldScalarError(err, envName, "unsupported slice/array element type", elemType.Kind())
+		}
+	}
+
+	return setNormalizedSliceOrArrayValues(fieldValue, newValue, envName, tagProp)
+}
+
+/*
+Set the slice or array value to the field, duplicates are removed before sorting
+*/
+func setNormalizedSliceOrArrayValues(fieldValue reflect.Value, newValue reflect.Value, envName string, tagProp tagProperties) error {
+	if tagProp.Unique {
+		var err error
+		if newValue, err = uniqueSliceOrArrayValues(newValue, envName); err != nil {
+			return err
+		}
+	}
+	if tagProp.SortOrder != "" {
+		if err := sortSliceOrArrayValues(newValue, envName, tagProp.SortOrder); err != nil {
+			return err
+		}
+	}
+
+	// Set the final value
+	fieldValue.Set(newValue)
+	return nil
+}
+
+/*
+Build the []string, []int and []bool slices with typed code and a single reflect.ValueOf,
+returns false for the other types which fall back to the reflective path
+*/
+func typedSliceValues(sliceType reflect.Type, values []string, envName string, tagProp tagProperties) (reflect.Value, bool, error) {
+	switch sliceType {
+	case stringSliceType:
+		strValues := make([]string, len(values))
+		for i, v := range values {
+			strVal := trimSliceElement(v, tagProp.NoTrim)
+			if tagProp.expandVars != nil {
+				expanded, err := tagProp.expandVars(strVal)
+				if err != nil {
+					return reflect.Value{}, false, fmt.Errorf("failed to expand %s: %w", envName, err)
+				}
+				strVal = expanded
+			}
+			strValues[i] = strVal
+		}
+		return reflect.ValueOf(strValues), true, nil
+	case intSliceType:
+		// byte sizes and units take the reflective path through convertScalar
+		if tagProp.ByteSize || tagProp.Unit != 0 {
+			break
+		}
+		intValues := make([]int, len(values))
+		for i, v := range values {
+			intValue, err := strconv.ParseInt(trimSliceElement(v, tagProp.NoTrim), tagProp.Base, strconv.IntSize)
+			if err != nil {
+				return reflect.Value{}, false, fmt.Errorf("failed to convert %s to int: %w", envName, err)
+			}
+			intValues[i] = int(intValue)
+		}
+		return reflect.ValueOf(intValues), true, nil
+	case boolSliceType:
+		boolValues := make([]bool, len(values))
+		for i, v := range values {
+			boolValue, err := parseBool(trimSliceElement(v, tagProp.NoTrim), tagProp.boolWords, tagProp.intBools)
+			if err != nil {
+				return reflect.Value{}, false, fmt.Errorf("error parsing env var %s: %w", envName, err)
+			}
+			boolValues[i] = boolValue
+		}
+		return reflect.ValueOf(boolValues), true, nil
+	}
+	return reflect.Value{}, false, nil
+}
+
+func uniqueSliceOrArrayValues(values reflect.Value, envName string) (reflect.Value, error) {
+	if !values.Type().Elem().Comparable() {
+		return values, fmt.Errorf("cannot apply unique to %s: element type %s is not comparable", envName, values.Type().Elem())
+	}
+	seen := make(map[any]struct{}, values.Len())
+	// keep the named slice type (e.g. type StringSet []string) of the field
+	sliceType := reflect.SliceOf(values.Type().Elem())
+	if values.Kind() == reflect.Slice {
+		sliceType = values.Type()
+	}
+	unique := reflect.MakeSlice(sliceType, 0, values.Len())
+	for i := range values.Len() {
+		elem := values.Index(i)
+		if _, ok := seen[elem.Interface()]; ok {
+			continue
+		}
+		seen[elem.Interface()] = struct{}{}
+		unique = reflect.Append(unique, elem)
+	}
+
+	// arrays have a fixed length so duplicates can not be dropped
+	if values.Kind() == reflect.Array {
+		if unique.Len() != values.Len() {
+			return values, fmt.Errorf("env var %s has duplicate values, but array expects %d unique values", envName, values.Len())
+		}
+		return values, nil
+	}
+	return unique, nil
+}
+
+func sortSliceOrArrayValues(values reflect.Value, envName string, order string) error {
+	// sort arrays in place through a slice sharing the same storage
+	if values.Kind() == reflect.Array {
+		values = values.Slice(0, values.Len())
+	}
+
+	var less func(i, j int) bool
+	switch values.Type().Elem().Kind() {
+	case reflect.String:
+		less = func(i, j int) bool { return values.Index(i).String() < values.Index(j).String() }
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		less = func(i, j int) bool { return values.Index(i).Int() < values.Index(j).Int() }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		less = func(i, j int) bool { return values.Index(i).Uint() < values.Index(j).Uint() }
+	case reflect.Float32, reflect.Float64:
+		less = func(i, j int) bool { return values.Index(i).Float() < values.Index(j).Float() }
+	default:
+		return fmt.Errorf("cannot apply sort to %s: element type %s is not orderable", envName, values.Type().Elem())
+	}
+
+	if order == "desc" {
+		sort.SliceStable(values.Interface(), func(i, j int) bool { return less(j, i) })
+		return nil
+	}
+	sort.SliceStable(values.Interface(), less)
+	return nil
+}
+
+func setEnvVarMapValues(fieldValue reflect.Value, envName string, envValue string, tagProp tagProperties) error {
+	// map[string]string is built with typed code, the unique keys check takes the reflective path
+	if fieldValue.Type() == stringMapType && !tagProp.uniqueMapKeys {
+		stringMap, err := parseStringMapEntries(envName, envValue, tagProp)
+		if err != nil {
+			return setEnvVarJSONMapFallback(fieldValue, envName, envValue, tagProp, err)
+		}
+		fieldValue.Set(reflect.ValueOf(stringMap))
+		return nil
+	}
+
+	// set the field value to the env var value
+	entries, err := parseMapEntries(fieldValue.Type().Key(), fieldValue.Type().Elem(), envName, envValue, tagProp)
+	if err != nil {
+		return setEnvVarJSONMapFallback(fieldValue, envName, envValue, tagProp, err)
+	}
+	newMap := reflect.MakeMapWithSize(fieldValue.Type(), len(entries))
+	for _, entry := range entries {
+		if tagProp.uniqueMapKeys && newMap.MapIndex(entry.key).IsValid() {
+			return fmt.Errorf("duplicate map key %q for %s", fmt.Sprint(entry.key.Interface()), envName)
+		}
+		newMap.SetMapIndex(entry.key, entry.value)
+	}
+
+	fieldValue.Set(newMap)
+	return nil
+}
+
+// setEnvVarJSONMapFallback falls back to a json object (e.g. {"a":"1,2"}), keeping the original error when it is not one
+func setEnvVarJSONMapFallback(fieldValue reflect.Value, envName string, envValue string, tagProp tagProperties, err error) error {
+	trimmed := strings.TrimSpace(envValue)
+	if tagProp.jsonMapFallback && strings.HasPrefix(trimmed, "{") && strings.HasSuffix(trimmed, "}") {
+		if jsonErr := setEnvVarJSONValues(fieldValue, envName, trimmed); jsonErr == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// mapEntry is a parsed key value entry of a map env var value
+type mapEntry struct {
+	key   reflect.Value
+	value reflect.Value
+}
+
+/*
+Split the map value into its entries on the delimiter, the delimiters inside the quoted keys
+and values (e.g. {dsn:"host=a,port=5432"}) and the escaped delimiters are kept, the quotes are
+kept for splitMapEntry so a quoted key may contain the ":"
+*/
+func splitMapEntries(value string, delimiter string) []string {
+	// values without quotes or escapes are split as is
+	if delimiter == "" || !strings.ContainsAny(value, `"'\`) {
+		return strings.Split(value, delimiter)
+	}
+	var entries []string
+	var entry strings.Builder
+	quoteChar := byte(0)
+	tokenStart := true
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case quoteChar != 0:
+			entry.WriteByte(c)
+			if c == quoteChar {
+				quoteChar = 0
+			}
+		case tokenStart && (c == '"' || c == '\''):
+			// only a quote opening the key or the value is special, so {name:it's} is kept as is
+			entry.WriteByte(c)
+			quoteChar = c
+			tokenStart = false
+		case c == '\\' && strings.HasPrefix(value[i+1:], delimiter):
+			// an escaped delimiter is kept as a literal, other backslashes are kept as is
+			entry.WriteString(delimiter)
+			tokenStart = false
+			i += len(delimiter)
+		case strings.HasPrefix(value[i:], delimiter):
+			entries = append(entries, entry.String())
+			entry.Reset()
+			tokenStart = true
+			i += len(delimiter) - 1
+		default:
+			entry.WriteByte(c)
+			switch c {
+			case ':':
+				tokenStart = true
+			case ' ', '\t':
+			default:
+				tokenStart = false
+			}
+		}
+	}
+	entries = append(entries, entry.String())
+	return entries
+}
+
+/*
+Split the map entry into its key and value at the first colon outside of quotes,
+so quoted keys and values can contain colons (e.g. "a:b":c)
+*/
+func splitMapEntry(entry string) (string, string, bool) {
+	entry = strings.TrimSpace(entry)
+	// skip the quoted key if any
+	keyEnd := 0
+	if len(entry) > 0 && (entry[0] == '"' || entry[0] == '\'') {
+		if index := strings.IndexByte(entry[1:], entry[0]); index >= 0 {
+			keyEnd = index + 2
+		}
+	}
+	index := strings.IndexByte(entry[keyEnd:], ':')
+	if index < 0 {
+		return "", "", false
+	}
+	index += keyEnd
+	return unquoteMapToken(entry[:index]), unquoteMapToken(entry[index+1:]), true
+}
+
+// unquoteMapToken trims the whitespace and the matching quotes of a map key or value
+func unquoteMapToken(token string) string {
+	token = strings.TrimSpace(token)
+	tokenLen := len(token)
+	if tokenLen >= 2 && token[0] == token[tokenLen-1] && (token[0] == '"' || token[0] == '\'') {
+		return token[1 : tokenLen-1]
+	}
+	return token
+}
+
+/*
+Parse the entries of a map env var value (e.g. {key1:value1,key2:value2}) in the order they are written
+*/
+func parseMapEntries(keyType reflect.Type, valueType reflect.Type, envName string, envValue string, tagProp tagProperties) ([]mapEntry, error) {
+	// trim the outer braces of the whole value once, so the spacing around them does not matter
+	envValue = strings.TrimPrefix(strings.TrimSpace(envValue), "{")
+	envValue = strings.TrimSuffix(strings.TrimSpace(envValue), "}")
+	mapValues := splitMapEntries(envValue, tagProp.Delimiter)
+	if tagProp.ignoreTrailingDelim {
+		mapValues = dropTrailingEmptyToken(mapValues)
+	}
+	entries := make([]mapEntry, 0, len(mapValues))
+
+	for _, pair := range mapValues {
+		key, value, ok := splitMapEntry(pair)
+		if !ok {
+			return nil, fmt.Errorf("invalid map entry for %s: %s", envName, pair)
+		}
+
+		mapKey := reflect.New(keyType).Elem()
+		mapValue := reflect.New(valueType).Elem()
+
+		// Set key
+		if err := convertScalar(mapKey, key, tagProp); err != nil {
+			return nil, mapScalarError(err, "key", key, mapKey.Kind())
+		}
+
+		// Set value, list values are split with the value delimiter, e.g. {groupA:1|2,groupB:3}
+		_, isText := textUnmarshaler(mapValue)
+		if !isText && (mapValue.Kind() == reflect.Slice || mapValue.Kind() == reflect.Array) {
+			valueTagProp := tagProp
+			valueTagProp.setDelimiter(tagProp.ValueDelimiter)
+			if err := setEnvVarSliceOrArrayValues(mapValue, envName, value, valueTagProp); err != nil {
+				return nil, err
+			}
+		} else if err := convertScalar(mapValue, value, tagProp); err != nil {
+			return nil, mapScalarError(err, "value", value, mapValue.Kind())
+		}
+
+		entries = append(entries, mapEntry{mapKey, mapValue})
+	}
+
+	return entries, nil
+}
+
+/*
+Parse the entries of a map[string]string env var value straight into the map, without
+the reflect.New of each key and value done by parseMapEntries
+*/
+func parseStringMapEntries(envName string, envValue string, tagProp tagProperties) (map[string]string, error) {
+	envValue = strings.TrimPrefix(strings.TrimSpace(envValue), "{")
+	envValue = strings.TrimSuffix(strings.TrimSpace(envValue), "}")
+	mapValues := splitMapEntries(envValue, tagProp.Delimiter)
+	if tagProp.ignoreTrailingDelim {
+		mapValues = dropTrailingEmptyToken(mapValues)
+	}
+	stringMap := make(map[string]string, len(mapValues))
+
+	for _, pair := range mapValues {
+		key, value, ok := splitMapEntry(pair)
+		if !ok {
+			return nil, fmt.Errorf("invalid map entry for %s: %s", envName, pair)
+		}
+		stringMap[key] = value
+	}
+
+	return stringMap, nil
+}
+
+// dropTrailingEmptyToken drops the empty last token left by a trailing delimiter, e.g. "a,b,c,"
+func dropTrailingEmptyToken(tokens []string) []string {
+	if len(tokens) > 1 && strings.TrimSpace(tokens[len(tokens)-1]) == "" {
+		return tokens[:len(tokens)-1]
+	}
+	return tokens
+}
+
+// tagPropertyKey returns the lowercased key of the tag property, the part before the "=" if any,
+// so the properties are matched as exact keys and not by the words in their values
+func tagPropertyKey(property string) string {
+	key, _, _ := strings.Cut(property, "=")
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+func checkAndSetTagPropRequired(property string, tagProp *tagProperties) error {
+	// the required field in prop is of type "required", "required=true" or "required=false"
+	if !strings.Contains(property, "=") {
+		tagProp.setRequired(true)
+		return nil
+	}
+	value := strings.ToLower(strings.TrimSpace(strings.SplitN(property, "=", 2)[1]))
+	value, _ = trimMatchingQuotes(value)
+	switch value {
+	case "true":
+		tagProp.setRequired(true)
+	case "false":
+		// an explicit required=false opts out of the required all option
+		tagProp.setRequired(false)
+		tagProp.setOptional(true)
+	default:
+		return fmt.Errorf("invalid required %s for %s: must be true or false", value, tagProp.EnvName)
+	}
+	return nil
+}
+
+func checkAndSetTagPropDefaultValue(property string, tagProp *tagProperties) {
+	if tagPropertyKey(property) != "default" {
+		return
+	}
+	if !strings.Contains(property, "=") {
+		return
+	}
+	property = strings.SplitN(property, "=", 2)[1]
+	property = strings.TrimSpace(property)
+	if unquoted, ok := trimMatchingQuotes(property); ok {
+		property = strings.TrimSpace(unquoted)
+	}
+	tagProp.setDefaultValue(property)
+	tagProp.setDefaultSet(true)
+}
+
+// trimMatchingQuotes strips the matching " or ' quotes wrapping the value, reporting whether it was quoted
+func trimMatchingQuotes(value string) (string, bool) {
+	valLen := len(value)
+	if valLen >= 2 {
+		first, last := value[0], value[valLen-1]
+		if (first == last) && (first == '"' || first == '\'') {
+			return value[1 : valLen-1], true
+		}
+	}
+	return value, false
+}
+
+func isEnvironmentDefaultProperty(property string) bool {
+	return strings.HasPrefix(tagPropertyKey(property), "default.")
+}
+
+func checkAndSetTagPropEnvironmentDefault(property string, tagProp *tagProperties) {
+	if !strings.Contains(property, "=") {
+		return
+	}
+	parts := strings.SplitN(property, "=", 2)
+	environment := strings.TrimSpace(strings.TrimPrefix(strings.ToLower(parts[0]), "default."))
+	property = strings.TrimSpace(parts[1])
+	if unquoted, ok := trimMatchingQuotes(property); ok {
+		property = strings.TrimSpace(unquoted)
+	}
+	tagProp.setEnvironmentDefault(environment, property)
+}
+
+func checkAndSetTagPropValueDelimiter(property string, tagProp *tagProperties) {
+	if !strings.Contains(property, "=") {
+		return
+	}
+	property = strings.SplitN(property, "=", 2)[1]
+	property = strings.TrimSpace(property)
+	property, _ = trimMatchingQuotes(property)
+	if property != "" {
+		tagProp.setValueDelimiter(property)
+	}
+}
+
+func checkAndSetTagPropDelimiterForSliceOrArray(property string, tagProp *tagProperties) {
+	if tagPropertyKey(property) != "delimiter" {
+		return
+	}
+	if !strings.Contains(property, "=") {
+		return
+	}
+	property = strings.SplitN(property, "=", 2)[1]
+	property = strings.TrimSpace(property)
+	property = strings.ToLower(property)
+	if unquoted, ok := trimMatchingQuotes(property); ok {
+		tagProp.setDelimiter(strings.TrimSpace(unquoted))
+	}
+}
+
+func cehckAndSetIsStringForByteOrRuneArray(property string, tagProp *tagProperties) {
+	if tagPropertyKey(property) != "isstring" {
+		return
+	}
+	// check if the required field is set to true or false
+	if strings.Contains(property, "=") {
+		property = strings.Split(property, "=")[1]
+		property = strings.TrimSpace(property)
+		property = strings.ToLower(property)
 	}
 	if strings.Contains(property, "true") {
 		tagProp.setIsString()
@@ -435,6 +1466,405 @@ func cehckAndSetIsStringForByteOrRuneArray(property string, tagProp *tagProperti
 	}
 }
 
+func checkAndSetTagPropUnique(property string, tagProp *tagProperties) {
+	property = strings.ToLower(property)
+	if tagPropertyKey(property) != "unique" {
+		return
+	}
+	// check if the unique field is set to true or false
+	if strings.Contains(property, "=") {
+		property = strings.TrimSpace(strings.SplitN(property, "=", 2)[1])
+		tagProp.setUnique(property != "false")
+		return
+	}
+	tagProp.setUnique(true)
+}
+
+func checkAndSetTagPropVersioned(property string, tagProp *tagProperties) {
+	property = strings.ToLower(property)
+	if tagPropertyKey(property) != "versioned" {
+		return
+	}
+	// check if the versioned field is set to true or false
+	if strings.Contains(property, "=") {
+		property = strings.TrimSpace(strings.SplitN(property, "=", 2)[1])
+		tagProp.setVersioned(property != "false")
+		return
+	}
+	tagProp.setVersioned(true)
+}
+
+func checkAndSetTagPropEncoding(property string, tagProp *tagProperties) error {
+	property = strings.ToLower(property)
+	if tagPropertyKey(property) != "encoding" || !strings.Contains(property, "=") {
+		return nil
+	}
+	property = strings.TrimSpace(strings.SplitN(property, "=", 2)[1])
+	property = strings.Trim(property, "'\"")
+	stages, err := parseEncodingStages(property)
+	if err != nil {
+		return fmt.Errorf("%w for %s", err, tagProp.EnvName)
+	}
+	tagProp.setEncoding(stages)
+	return nil
+}
+
+func checkAndSetTagPropHex(property string, tagProp *tagProperties) {
+	property = strings.ToLower(property)
+	if tagPropertyKey(property) != "hex" {
+		return
+	}
+	// check if the hex field is set to true or false
+	if strings.Contains(property, "=") && strings.TrimSpace(strings.SplitN(property, "=", 2)[1]) == "false" {
+		return
+	}
+	// hex is the outermost encoding stage
+	tagProp.setEncoding(append(slices.Clip(tagProp.Encoding), encodingHex))
+}
+
+func checkAndSetTagPropJSON(property string, tagProp *tagProperties) {
+	property = strings.ToLower(property)
+	if tagPropertyKey(property) != "json" {
+		return
+	}
+	// check if the json field is set to true or false
+	if strings.Contains(property, "=") {
+		property = strings.TrimSpace(strings.SplitN(property, "=", 2)[1])
+		tagProp.setJSON(property != "false")
+		return
+	}
+	tagProp.setJSON(true)
+}
+
+func checkAndSetTagPropByteSize(property string, tagProp *tagProperties) {
+	property = strings.ToLower(property)
+	if tagPropertyKey(property) != "bytesize" {
+		return
+	}
+	// check if the bytesize field is set to true or false
+	if strings.Contains(property, "=") {
+		property = strings.TrimSpace(strings.SplitN(property, "=", 2)[1])
+		tagProp.setByteSize(property != "false")
+		return
+	}
+	tagProp.setByteSize(true)
+}
+
+func checkAndSetTagPropNoTrim(property string, tagProp *tagProperties) {
+	property = strings.ToLower(property)
+	if tagPropertyKey(property) != "notrim" {
+		return
+	}
+	// check if the notrim field is set to true or false
+	if strings.Contains(property, "=") {
+		property = strings.TrimSpace(strings.SplitN(property, "=", 2)[1])
+		tagProp.setNoTrim(property != "false")
+		return
+	}
+	tagProp.setNoTrim(true)
+}
+
+func checkAndSetTagPropSecret(property string, tagProp *tagProperties) {
+	property = strings.ToLower(property)
+	if tagPropertyKey(property) != "secret" {
+		return
+	}
+	// check if the secret field is set to true or false
+	if strings.Contains(property, "=") {
+		property = strings.TrimSpace(strings.SplitN(property, "=", 2)[1])
+		tagProp.setSecret(property != "false")
+		return
+	}
+	tagProp.setSecret(true)
+}
+
+func checkAndSetTagPropInfer(property string, tagProp *tagProperties) {
+	property = strings.ToLower(property)
+	if tagPropertyKey(property) != "infer" {
+		return
+	}
+	// check if the infer field is set to true or false
+	if strings.Contains(property, "=") {
+		property = strings.TrimSpace(strings.SplitN(property, "=", 2)[1])
+		tagProp.setInfer(property != "false")
+		return
+	}
+	tagProp.setInfer(true)
+}
+
+func checkAndSetTagPropMultiline(property string, tagProp *tagProperties) {
+	property = strings.ToLower(property)
+	if tagPropertyKey(property) != "multiline" {
+		return
+	}
+	// check if the multiline field is set to true or false
+	if strings.Contains(property, "=") {
+		property = strings.TrimSpace(strings.SplitN(property, "=", 2)[1])
+		tagProp.setMultiline(property != "false")
+		return
+	}
+	tagProp.setMultiline(true)
+}
+
+func checkAndSetTagPropFromFile(property string, tagProp *tagProperties) {
+	property = strings.ToLower(property)
+	if tagPropertyKey(property) != "fromfile" {
+		return
+	}
+	// check if the fromfile field is set to true or false
+	if strings.Contains(property, "=") {
+		property = strings.TrimSpace(strings.SplitN(property, "=", 2)[1])
+		tagProp.setFromFile(property != "false")
+		return
+	}
+	tagProp.setFromFile(true)
+}
+
+func checkAndSetTagPropPercent(property string, tagProp *tagProperties) {
+	property = strings.ToLower(property)
+	if tagPropertyKey(property) != "percent" {
+		return
+	}
+	// check if the percent field is set to true or false
+	if strings.Contains(property, "=") {
+		property = strings.TrimSpace(strings.SplitN(property, "=", 2)[1])
+		tagProp.setPercent(property != "false")
+		return
+	}
+	tagProp.setPercent(true)
+}
+
+func checkAndSetTagPropBase(property string, tagProp *tagProperties) error {
+	property = strings.ToLower(property)
+	if tagPropertyKey(property) != "base" || !strings.Contains(property, "=") {
+		return nil
+	}
+	property = strings.TrimSpace(strings.SplitN(property, "=", 2)[1])
+	property = strings.Trim(property, "'\"")
+	// base 0 detects the base from the 0x, 0o and 0b prefixes
+	base, err := strconv.Atoi(property)
+	if err != nil || base == 1 || base < 0 || base > 36 {
+		return fmt.Errorf("invalid base %s for %s: must be 0 or between 2 and 36", property, tagProp.EnvName)
+	}
+	tagProp.setBase(base)
+	return nil
+}
+
+// durationUnits are the units of the unit property by name
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond, "nanosecond": time.Nanosecond, "nanoseconds": time.Nanosecond,
+	"us": time.Microsecond, "µs": time.Microsecond, "microsecond": time.Microsecond, "microseconds": time.Microsecond,
+	"ms": time.Millisecond, "millisecond": time.Millisecond, "milliseconds": time.Millisecond,
+	"s": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hour": time.Hour, "hours": time.Hour,
+}
+
+func checkAndSetTagPropUnit(property string, tagProp *tagProperties) error {
+	property = strings.ToLower(property)
+	if tagPropertyKey(property) != "unit" || !strings.Contains(property, "=") {
+		return nil
+	}
+	property = strings.TrimSpace(strings.SplitN(property, "=", 2)[1])
+	property = strings.Trim(property, "'\"")
+	unit, ok := durationUnits[property]
+	if !ok {
+		return fmt.Errorf("invalid unit %s for %s: must be one of ns, us, ms, s, m or h", property, tagProp.EnvName)
+	}
+	tagProp.setUnit(unit)
+	return nil
+}
+
+func checkAndSetTagPropSortOrder(property string, tagProp *tagProperties) error {
+	property = strings.ToLower(property)
+	if tagPropertyKey(property) != "sort" {
+		return nil
+	}
+	// sort without a value defaults to ascending order
+	if !strings.Contains(property, "=") {
+		tagProp.setSortOrder("asc")
+		return nil
+	}
+	property = strings.TrimSpace(strings.SplitN(property, "=", 2)[1])
+	property = strings.Trim(property, "'\"")
+	if property != "asc" && property != "desc" {
+		return fmt.Errorf("invalid sort order %s for %s: must be asc or desc", property, tagProp.EnvName)
+	}
+	tagProp.setSortOrder(property)
+	return nil
+}
+
+func checkAndSetTagPropMessage(property string, tagProp *tagProperties) {
+	if !strings.Contains(property, "=") {
+		return
+	}
+	property = strings.SplitN(property, "=", 2)[1]
+	property = strings.TrimSpace(property)
+	property, _ = trimMatchingQuotes(property)
+	tagProp.setMessage(property)
+}
+
+func checkAndSetTagPropLayout(property string, tagProp *tagProperties) {
+	if !strings.Contains(property, "=") {
+		return
+	}
+	property = strings.SplitN(property, "=", 2)[1]
+	property = strings.TrimSpace(property)
+	property, _ = trimMatchingQuotes(property)
+	tagProp.setLayout(property)
+}
+
+// checkAndSetTagPropRequiredIf sets the condition of requiredif=OTHER=value
+func checkAndSetTagPropRequiredIf(property string, tagProp *tagProperties) error {
+	if !strings.Contains(property, "=") {
+		return nil
+	}
+	property = strings.TrimSpace(strings.SplitN(property, "=", 2)[1])
+	property, _ = trimMatchingQuotes(property)
+	envName, value, ok := strings.Cut(property, "=")
+	envName = strings.TrimSpace(envName)
+	if !ok || envName == "" {
+		return fmt.Errorf("invalid requiredif %s for %s: must be OTHER=value", property, tagProp.EnvName)
+	}
+	tagProp.setRequiredIf(envName, strings.TrimSpace(value))
+	return nil
+}
+
+func checkAndSetTagPropGroup(property string, tagProp *tagProperties) {
+	if !strings.Contains(property, "=") {
+		return
+	}
+	property = strings.SplitN(property, "=", 2)[1]
+	property = strings.TrimSpace(property)
+	if unquoted, ok := trimMatchingQuotes(property); ok {
+		property = strings.TrimSpace(unquoted)
+	}
+	tagProp.setGroup(property)
+}
+
+func checkAndSetTagPropDeprecated(property string, tagProp *tagProperties) {
+	if !strings.Contains(property, "=") {
+		return
+	}
+	property = strings.SplitN(property, "=", 2)[1]
+	property = strings.TrimSpace(property)
+	property, _ = trimMatchingQuotes(property)
+
+	var deprecated []string
+	for _, name := range strings.Split(property, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			deprecated = append(deprecated, name)
+		}
+	}
+	tagProp.setDeprecated(deprecated)
+}
+
+func checkAndSetTagPropForbidden(property string, tagProp *tagProperties) {
+	if !strings.Contains(property, "=") {
+		return
+	}
+	property = strings.SplitN(property, "=", 2)[1]
+	property = strings.TrimSpace(property)
+	property, _ = trimMatchingQuotes(property)
+
+	var forbidden []string
+	for _, value := range strings.Split(property, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			forbidden = append(forbidden, value)
+		}
+	}
+	tagProp.setForbidden(forbidden)
+}
+
+func checkAndSetTagPropPattern(property string, tagProp *tagProperties) error {
+	if !strings.Contains(property, "=") {
+		return nil
+	}
+	// the pattern is case sensitive so it is not lowercased
+	property = strings.SplitN(property, "=", 2)[1]
+	property = strings.TrimSpace(property)
+	property, _ = trimMatchingQuotes(property)
+
+	// compile the pattern only once and reuse it for every field using it
+	if cached, ok := compiledPatterns.Load(property); ok {
+		tagProp.setPattern(property, cached.(*regexp.Regexp))
+		return nil
+	}
+	regex, err := regexp.Compile(property)
+	if err != nil {
+		return fmt.Errorf("invalid pattern %s for %s: %w", property, tagProp.EnvName, err)
+	}
+	compiledPatterns.Store(property, regex)
+	tagProp.setPattern(property, regex)
+	return nil
+}
+
+/*
+Split the env var value on the delimiter, the elements starting with a quote are kept
+whole up to the closing quote (e.g. a,"b,c",d is split into a, b,c and d) and a
+backslash escapes the delimiter (e.g. a\,b,c is split into a,b and c), a quote not closed
+at the end of its element is kept as is (e.g. 'tis,foo is split into 'tis and foo)
+*/
+func splitValueRespectingQuotes(value string, delimiter string) []string {
+	// values without quotes or escapes are split as is
+	if delimiter == "" || !strings.ContainsAny(value, `"'\`) {
+		return strings.Split(value, delimiter)
+	}
+	var parts []string
+	var part strings.Builder
+	elementStart := true
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		// only a balanced quote opening the element is special, so "it's" and 'tis are kept as is
+		closing := -1
+		if elementStart && (c == '"' || c == '\'') {
+			closing = quotedElementEnd(value[i+1:], c, delimiter)
+		}
+		switch {
+		case closing >= 0:
+			part.Reset()
+			part.WriteString(value[i+1 : i+1+closing])
+			elementStart = false
+			i += 1 + closing
+		case c == '\\' && strings.HasPrefix(value[i+1:], delimiter):
+			// an escaped delimiter is kept as a literal, other backslashes are kept as is
+			part.WriteString(delimiter)
+			elementStart = false
+			i += len(delimiter)
+		case strings.HasPrefix(value[i:], delimiter):
+			parts = append(parts, part.String())
+			part.Reset()
+			elementStart = true
+			i += len(delimiter) - 1
+		default:
+			part.WriteByte(c)
+			if c != ' ' && c != '\t' {
+				elementStart = false
+			}
+		}
+	}
+	parts = append(parts, part.String())
+	return parts
+}
+
+// quotedElementEnd returns the index of the quote closing the element in the rest of the value,
+// -1 when the quote is not closed right before the delimiter or the end of the value
+func quotedElementEnd(rest string, quote byte, delimiter string) int {
+	closing := strings.IndexByte(rest, quote)
+	if closing < 0 {
+		return -1
+	}
+	after := rest[closing+1:]
+	if strings.HasPrefix(after, delimiter) {
+		return closing
+	}
+	if after = strings.TrimLeft(after, " \t"); after == "" || strings.HasPrefix(after, delimiter) {
+		return closing
+	}
+	return -1
+}
+
 func splitTagRespectingQuotes(tag string) []string {
 	var parts []string
 	var part strings.Builder
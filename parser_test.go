@@ -2,6 +2,339 @@
 
 package envarfig
 
-import "testing"
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
 
 func TestParser(t *testing.T) {}
+
+func TestSplitValueRespectingQuotes(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		delimiter string
+		expected  []string
+	}{
+		{"No quotes", "a,b,c", ",", []string{"a", "b", "c"}},
+		{"Double quoted element", `a,"b,c",d`, ",", []string{"a", "b,c", "d"}},
+		{"Single quoted element", `a; 'b;c' ;d`, ";", []string{"a", "b;c ", "d"}},
+		{"Apostrophe inside element", "it's,fine", ",", []string{"it's", "fine"}},
+		{"Multi character delimiter", `a::"b::c"::d`, "::", []string{"a", "b::c", "d"}},
+		{"Empty value", "", ",", []string{""}},
+		{"Escaped delimiter", `a\,b,c`, ",", []string{"a,b", "c"}},
+		{"Escaped multi character delimiter", `a\::b::c`, "::", []string{"a::b", "c"}},
+		{"Backslash without delimiter", `C:\dir,d`, ",", []string{`C:\dir`, "d"}},
+		{"Trailing backslash", `a,b\`, ",", []string{"a", `b\`}},
+		{"Leading apostrophe", "'tis,foo", ",", []string{"'tis", "foo"}},
+		{"Unterminated quote", `a,"b,c`, ",", []string{"a", `"b`, "c"}},
+		{"Quote closed inside element", `"b,c"d,e`, ",", []string{`"b`, `c"d`, "e"}},
+		{"Spaces after closing quote", `"b,c" ,d`, ",", []string{"b,c ", "d"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, splitValueRespectingQuotes(tt.value, tt.delimiter))
+		})
+	}
+}
+
+func TestSetEnvVarStringSliceValues(t *testing.T) {
+	// the byte and rune slices with isstring take the value whole, stray quotes included
+	tagProp := tagProperties{Delimiter: ",", Base: 10, isString: true}
+	var bytesValue []byte
+	assert.NoError(t, setEnvVarSliceOrArrayValues(reflect.ValueOf(&bytesValue).Elem(), "BYTES", `"abc`, tagProp))
+	assert.Equal(t, []byte(`"abc`), bytesValue)
+	var runesValue []rune
+	assert.NoError(t, setEnvVarSliceOrArrayValues(reflect.ValueOf(&runesValue).Elem(), "RUNES", `'a,b`, tagProp))
+	assert.Equal(t, []rune(`'a,b`), runesValue)
+
+	// a stray apostrophe is kept in the string elements
+	var strValues []string
+	assert.NoError(t, setEnvVarSliceOrArrayValues(reflect.ValueOf(&strValues).Elem(), "STRINGS", "'tis,foo", tagProperties{Delimiter: ",", Base: 10}))
+	assert.Equal(t, []string{"'tis", "foo"}, strValues)
+}
+
+func TestSplitMapEntry(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry string
+		key   string
+		value string
+		ok    bool
+	}{
+		{"Plain entry", "a:1", "a", "1", true},
+		{"Padded entry", " a : 1 ", "a", "1", true},
+		{"URL value", "api:http://x:8080", "api", "http://x:8080", true},
+		{"Double quoted key", `"a:b":c`, "a:b", "c", true},
+		{"Single quoted key", `'a:b' : c`, "a:b", "c", true},
+		{"Quoted value", `a:"b:c"`, "a", "b:c", true},
+		{"Unterminated quote", `"a:b`, `"a`, "b", true},
+		{"Missing colon", "a", "", "", false},
+		{"Quoted key without colon", `"a:b"`, "", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, value, ok := splitMapEntry(test.entry)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.key, key)
+			assert.Equal(t, test.value, value)
+		})
+	}
+}
+
+func TestParseTagPropertyKeys(t *testing.T) {
+	tests := []struct {
+		name         string
+		tag          string
+		envName      string
+		defaultValue string
+		required     bool
+		delimiter    string
+	}{
+		{"Env name containing required", "IS_REQUIRED_FLAG", "IS_REQUIRED_FLAG", "", false, ","},
+		{"Env name containing default", "DEFAULT_REGION,default=eu", "DEFAULT_REGION", "eu", false, ","},
+		{"Default containing default", "MODE,default='use the default'", "MODE", "use the default", false, ","},
+		{"Default containing required", "MODE,default=not_required", "MODE", "not_required", false, ","},
+		{"Default of required", "MODE,default=required", "MODE", "required", false, ","},
+		{"Default required by default", "MODE,default=required_by_default", "MODE", "required_by_default", false, ","},
+		{"Default containing delimiter", "MODE,default=delimiter", "MODE", "delimiter", false, ","},
+		{"Padded keys", "MODE, default = fast , required , delimiter = ';'", "MODE", "fast", true, ";"},
+		{"Upper case keys", "MODE,DEFAULT=fast,REQUIRED=false", "MODE", "fast", false, ","},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tagProp, err := parseTagAndTagValues(tt.tag)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.envName, tagProp.EnvName)
+			assert.Equal(t, tt.defaultValue, tagProp.DefaultValue)
+			assert.Equal(t, tt.required, tagProp.Required)
+			assert.Equal(t, tt.delimiter, tagProp.Delimiter)
+		})
+	}
+}
+
+func TestParseTagPropertyErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		tag  string
+		err  string
+	}{
+		{"Misspelled required", "MODE,requried", "unknown tag option requried for MODE"},
+		{"Misspelled default", "MODE,defualt=fast", "unknown tag option defualt for MODE"},
+		{"Required containing true", "MODE,required=untrue", "invalid required untrue for MODE"},
+		{"Required containing false", "MODE,required=falsey", "invalid required falsey for MODE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTagAndTagValues(tt.tag)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+// benchConfig is a 30 field config for the tag parsing benchmarks
+type benchConfig struct {
+	Field01 string            `env:"FIELD_01,default=value"`
+	Field02 int               `env:"FIELD_02,default=2"`
+	Field03 bool              `env:"FIELD_03,default=true"`
+	Field04 []string          `env:"FIELD_04,default='a;b;c',delimiter=';'"`
+	Field05 map[string]int    `env:"FIELD_05,default='{a:1,b:2}'"`
+	Field06 string            `env:"FIELD_06,default=value,pattern=^[a-z]+$"`
+	Field07 uint              `env:"FIELD_07,default=ff,base=16"`
+	Field08 float64           `env:"FIELD_08,default=0.5"`
+	Field09 []int             `env:"FIELD_09,default='3,1,2',sort,unique"`
+	Field10 string            `env:"FIELD_10,default=value,forbid=changeme"`
+	Field11 string            `env:"FIELD_11,default=value"`
+	Field12 int               `env:"FIELD_12,default=12"`
+	Field13 bool              `env:"FIELD_13,default=false"`
+	Field14 []string          `env:"FIELD_14,default='a,b'"`
+	Field15 map[string]string `env:"FIELD_15,default={a:b}"`
+	Field16 string            `env:"FIELD_16,default=value,default.staging=staging"`
+	Field17 int64             `env:"FIELD_17,default=17"`
+	Field18 float32           `env:"FIELD_18,default=1.5"`
+	Field19 []uint            `env:"FIELD_19,default='1,2,3'"`
+	Field20 string            `env:"FIELD_20,default=value"`
+	Field21 string            `env:"FIELD_21,default=value"`
+	Field22 int               `env:"FIELD_22,default=22"`
+	Field23 bool              `env:"FIELD_23,default=true"`
+	Field24 []string          `env:"FIELD_24,default='x|y',delimiter='|'"`
+	Field25 map[string]bool   `env:"FIELD_25,default={a:true}"`
+	Field26 string            `env:"FIELD_26,default=value"`
+	Field27 int32             `env:"FIELD_27,default=27"`
+	Field28 float64           `env:"FIELD_28,default=2.5"`
+	Field29 []float64         `env:"FIELD_29,default='1.5,2.5'"`
+	Field30 string            `env:"FIELD_30,default=value"`
+}
+
+func BenchmarkParseEnvVar(b *testing.B) {
+	b.Run("Cached tags", func(b *testing.B) {
+		for range b.N {
+			var config benchConfig
+			if err := parseEnvVar(&config, loadSettings()); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Uncached tags", func(b *testing.B) {
+		key := parsedTagsKey{reflect.TypeOf(benchConfig{}), defaultTagName}
+		for range b.N {
+			parsedTags.Delete(key)
+			var config benchConfig
+			if err := parseEnvVar(&config, loadSettings()); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// benchStrings is a named slice type, which takes the reflective slice path
+type benchStrings []string
+
+func BenchmarkSetEnvVarSliceOrArrayValues(b *testing.B) {
+	tagProp := tagProperties{Delimiter: ",", Base: 10}
+	envValue := "alpha, beta, gamma, delta, epsilon, zeta, eta, theta"
+	b.Run("Typed string slice", func(b *testing.B) {
+		b.ReportAllocs()
+		var values []string
+		for range b.N {
+			if err := setEnvVarSliceOrArrayValues(reflect.ValueOf(&values).Elem(), "VALUES", envValue, tagProp); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Reflective string slice", func(b *testing.B) {
+		b.ReportAllocs()
+		var values benchStrings
+		for range b.N {
+			if err := setEnvVarSliceOrArrayValues(reflect.ValueOf(&values).Elem(), "VALUES", envValue, tagProp); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Typed int slice", func(b *testing.B) {
+		b.ReportAllocs()
+		var values []int
+		for range b.N {
+			if err := setEnvVarSliceOrArrayValues(reflect.ValueOf(&values).Elem(), "VALUES", "1,2,3,4,5,6,7,8", tagProp); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Reflective int slice", func(b *testing.B) {
+		b.ReportAllocs()
+		var values []int64
+		for range b.N {
+			if err := setEnvVarSliceOrArrayValues(reflect.ValueOf(&values).Elem(), "VALUES", "1,2,3,4,5,6,7,8", tagProp); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestParseTagDefaultCase(t *testing.T) {
+	// the default values keep their case, e.g. log levels and URLs
+	tagProp, err := parseTagAndTagValues("LEVEL,default=Info,default.staging='Debug Mode'")
+	assert.NoError(t, err)
+	assert.Equal(t, "Info", tagProp.DefaultValue)
+	assert.Equal(t, map[string]string{"staging": "Debug Mode"}, tagProp.EnvironmentDefaults)
+}
+
+func TestSplitMapEntries(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		delimiter string
+		expected  []string
+	}{
+		{"No quotes", "a:1,b:2", ",", []string{"a:1", "b:2"}},
+		{"Double quoted value", `dsn:"host=a,port=5432",b:2`, ",", []string{`dsn:"host=a,port=5432"`, "b:2"}},
+		{"Single quoted value with spaces", `a: 'x;y' ;b:2`, ";", []string{`a: 'x;y' `, "b:2"}},
+		{"Quoted key", `"a,b":1,c:2`, ",", []string{`"a,b":1`, "c:2"}},
+		{"Apostrophe inside value", "a:it's,b:fine", ",", []string{"a:it's", "b:fine"}},
+		{"Escaped delimiter", `a:x\,y,b:2`, ",", []string{"a:x,y", "b:2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, splitMapEntries(tt.value, tt.delimiter))
+		})
+	}
+}
+
+func TestNormalizeComplex(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+		valid    bool
+	}{
+		{"1+2i", "1+2i", true},
+		{" 1 + 2i ", "1+2i", true},
+		{"3 - 4i", "3-4i", true},
+		{"( 1 + 2i )", "(1+2i)", true},
+		{"-1.5e+3 - 2i", "-1.5e+3-2i", true},
+		{"1 2i", "1 2i", false},
+		{"1 + 2 i", "1+2 i", false},
+		{"1 2 + 3i", "1 2+3i", false},
+	}
+	for _, test := range tests {
+		normalized := normalizeComplex(test.value)
+		assert.Equal(t, test.expected, normalized, test.value)
+		_, err := strconv.ParseComplex(normalized, 128)
+		assert.Equal(t, test.valid, err == nil, test.value)
+	}
+}
+
+func TestSetEnvVarStringMapValues(t *testing.T) {
+	tagProp := tagProperties{Delimiter: ",", Base: 10}
+	envValue := `{host:localhost, "dsn":"a=1,b=2", 'x:y':z}`
+
+	// the typed map[string]string path parses like the reflective path of a named map
+	var typed map[string]string
+	assert.NoError(t, setEnvVarMapValues(reflect.ValueOf(&typed).Elem(), "VALUES", envValue, tagProp))
+	var reflective benchStringMap
+	assert.NoError(t, setEnvVarMapValues(reflect.ValueOf(&reflective).Elem(), "VALUES", envValue, tagProp))
+	assert.Equal(t, map[string]string{"host": "localhost", "dsn": "a=1,b=2", "x:y": "z"}, typed)
+	assert.Equal(t, map[string]string(reflective), typed)
+
+	err := setEnvVarMapValues(reflect.ValueOf(&typed).Elem(), "VALUES", "{host}", tagProp)
+	assert.EqualError(t, err, "invalid map entry for VALUES: host")
+}
+
+// benchStringMap is a named map type, which takes the reflective map path
+type benchStringMap map[string]string
+
+func BenchmarkSetEnvVarMapValues(b *testing.B) {
+	tagProp := tagProperties{Delimiter: ",", Base: 10}
+	entries := make([]string, 1000)
+	for i := range entries {
+		entries[i] = fmt.Sprintf("key%d:value%d", i, i)
+	}
+	envValue := "{" + strings.Join(entries, ",") + "}"
+	b.Run("Typed string map", func(b *testing.B) {
+		b.ReportAllocs()
+		var values map[string]string
+		for range b.N {
+			if err := setEnvVarMapValues(reflect.ValueOf(&values).Elem(), "VALUES", envValue, tagProp); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Reflective string map", func(b *testing.B) {
+		b.ReportAllocs()
+		var values benchStringMap
+		for range b.N {
+			if err := setEnvVarMapValues(reflect.ValueOf(&values).Elem(), "VALUES", envValue, tagProp); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
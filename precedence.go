@@ -0,0 +1,71 @@
+package envarfig
+
+import "slices"
+
+// ValueSource is a source of the field values, ordered with WithPrecedence
+type ValueSource int
+
+const (
+	// SourceProcessEnv is the env source, the process env unless set with WithEnvSource
+	SourceProcessEnv ValueSource = iota + 1
+	// SourceEnvFiles is the parsed env content of WithEnvReader, WithEnvContent and WithLayeredFiles,
+	// the env files loaded with godotenv are part of the process env unless WithPrecedence is set
+	SourceEnvFiles
+	// SourceTagDefault is the default of the env tag, e.g. `env:"PORT,default=8080"`
+	SourceTagDefault
+	// SourceDefaults is the defaults map of WithDefaults
+	SourceDefaults
+)
+
+func (s ValueSource) String() string {
+	switch s {
+	case SourceProcessEnv:
+		return "env"
+	case SourceEnvFiles:
+		return "envfile"
+	case SourceTagDefault:
+		return "default"
+	case SourceDefaults:
+		return "defaults"
+	}
+	return "unset"
+}
+
+/*
+Get the order of the value sources, the configured precedence or else the process env,
+the env files, the tag default and the defaults map, with the env files first when overload is set
+*/
+func (s *settings) precedence() []ValueSource {
+	if s.Precedence != nil {
+		return s.Precedence
+	}
+	if s.OverloadEnv {
+		return []ValueSource{SourceEnvFiles, SourceProcessEnv, SourceTagDefault, SourceDefaults}
+	}
+	return []ValueSource{SourceProcessEnv, SourceEnvFiles, SourceTagDefault, SourceDefaults}
+}
+
+// sourceRank returns the position of the source in the precedence, -1 when it is not used
+func (s *settings) sourceRank(source ValueSource) int {
+	return slices.Index(s.precedence(), source)
+}
+
+/*
+Pick the default of the field from the tag default and the defaults map by precedence,
+returns the empty default when neither is set or used
+*/
+func (s *settings) fieldDefault(tagDefault string, envName string) (string, ValueSource) {
+	for _, source := range s.precedence() {
+		switch source {
+		case SourceTagDefault:
+			if tagDefault != "" {
+				return tagDefault, source
+			}
+		case SourceDefaults:
+			if defaultValue, ok := s.Defaults[envName]; ok && defaultValue != "" {
+				return defaultValue, source
+			}
+		}
+	}
+	return "", 0
+}
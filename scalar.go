@@ -0,0 +1,312 @@
+package envarfig
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errUnsupportedScalar is returned by convertScalar for the kinds it can not convert
+var errUnsupportedScalar = errors.New("unsupported scalar type")
+
+// scalarError is a failed scalar conversion, typeName is the name used in the error messages (e.g. "int")
+type scalarError struct {
+	typeName string
+	err      error
+}
+
+func (e *scalarError) Error() string {
+	return fmt.Sprintf("failed to convert to %s: %s", e.typeName, e.err)
+}
+
+func (e *scalarError) Unwrap() error {
+	return e.err
+}
+
+/*
+info: converts the raw value into the scalar target, shared by the fields, the slice and array
+elements and the map keys and values so they all parse the same way
+
+useage: convertScalar(reflect.ValueOf(&timeout).Elem(), "30s", tagProp)
+
+args:
+  - target: the settable value to convert into
+  - raw: the raw value
+  - tagProp: the tag properties of the field (base, bytesize, unit, percent, layout, isstring, bool words)
+*/
+func convertScalar(target reflect.Value, raw string, tagProp tagProperties) error {
+	// times with a layout (e.g. layout=2006-01-02) are parsed with it instead of RFC 3339
+	if tagProp.Layout != "" && target.Type() == timeType {
+		if raw == "" {
+			return nil
+		}
+		timeValue, err := time.Parse(tagProp.Layout, raw)
+		if err != nil {
+			return &scalarError{"time", err}
+		}
+		target.Set(reflect.ValueOf(timeValue))
+		return nil
+	}
+
+	// types unmarshaling themselves from text (e.g. netip.Addr, time.Time), unset values keep the zero value
+	if unmarshaler, ok := textUnmarshaler(target); ok {
+		if raw == "" {
+			return nil
+		}
+		if err := unmarshaler.UnmarshalText([]byte(raw)); err != nil {
+			return &scalarError{"text", err}
+		}
+		return nil
+	}
+
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// runes are taken from a single character with isstring (e.g. SEP=;), unset values keep the zero value
+		if tagProp.isString && target.Kind() == reflect.Int32 {
+			if raw == "" {
+				return nil
+			}
+			runes := []rune(raw)
+			if len(runes) != 1 {
+				return &scalarError{"rune", fmt.Errorf("%q is not a single character", raw)}
+			}
+			target.SetInt(int64(runes[0]))
+			return nil
+		}
+		// human byte sizes like "10MB" are parsed into the byte count
+		if tagProp.ByteSize {
+			size, err := parseByteSize(raw)
+			if err == nil && target.OverflowInt(size) {
+				err = fmt.Errorf("size %q out of range", raw)
+			}
+			if err != nil {
+				return &scalarError{"bytesize", err}
+			}
+			target.SetInt(size)
+			return nil
+		}
+		// bare numbers are in the unit of the field (e.g. unit=seconds), durations like "1m" are converted into it
+		if tagProp.Unit != 0 {
+			return convertUnitValue(target, raw, tagProp)
+		}
+		// durations are int64 parsed from strings like "30s"
+		if target.Type() == durationType {
+			duration, err := time.ParseDuration(raw)
+			if err != nil {
+				return &scalarError{"duration", err}
+			}
+			target.SetInt(int64(duration))
+			return nil
+		}
+		intValue, err := strconv.ParseInt(raw, tagProp.Base, target.Type().Bits())
+		if err != nil {
+			return &scalarError{"int", err}
+		}
+		target.SetInt(intValue)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if tagProp.ByteSize {
+			size, err := parseByteSize(raw)
+			if err == nil && target.OverflowUint(uint64(size)) {
+				err = fmt.Errorf("size %q out of range", raw)
+			}
+			if err != nil {
+				return &scalarError{"bytesize", err}
+			}
+			target.SetUint(uint64(size))
+			return nil
+		}
+		uintValue, err := strconv.ParseUint(raw, tagProp.Base, target.Type().Bits())
+		if err != nil {
+			return &scalarError{"uint", err}
+		}
+		target.SetUint(uintValue)
+	case reflect.Float32, reflect.Float64:
+		// percentages like "75%" are parsed into fractions like 0.75
+		if tagProp.Percent {
+			percentValue, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(raw), "%")), 64)
+			if err != nil {
+				return &scalarError{"percent", err}
+			}
+			// NaN and the infinities are not percentages, nor are the fractions overflowing a float32
+			if math.IsNaN(percentValue) || math.IsInf(percentValue, 0) || target.OverflowFloat(percentValue/100) {
+				return &scalarError{"percent", fmt.Errorf("%s out of range", raw)}
+			}
+			target.SetFloat(percentValue / 100)
+			return nil
+		}
+		floatValue, err := strconv.ParseFloat(raw, target.Type().Bits())
+		if err != nil {
+			return &scalarError{"float", err}
+		}
+		target.SetFloat(floatValue)
+	case reflect.Complex64, reflect.Complex128:
+		complexValue, err := strconv.ParseComplex(normalizeComplex(raw), target.Type().Bits())
+		if err != nil {
+			return &scalarError{"complex", err}
+		}
+		target.SetComplex(complexValue)
+	case reflect.Bool:
+		boolValue, err := parseBool(raw, tagProp.boolWords, tagProp.intBools)
+		if err != nil {
+			return &scalarError{"bool", err}
+		}
+		target.SetBool(boolValue)
+	case reflect.Interface:
+		if tagProp.Infer {
+			target.Set(reflect.ValueOf(inferScalar(raw)))
+			return nil
+		}
+		target.Set(reflect.ValueOf(raw))
+	default:
+		return errUnsupportedScalar
+	}
+	return nil
+}
+
+/*
+Infer the type of the raw value of an interface target, integers become int, the other
+numbers float64 and true/false a bool, anything else is kept as a string
+*/
+func inferScalar(raw string) any {
+	if intValue, err := strconv.ParseInt(raw, 10, 0); err == nil {
+		return int(intValue)
+	}
+	// "Inf" and "NaN" are parsed as floats, so only the values with digits are numbers
+	if strings.ContainsAny(raw, "0123456789") {
+		if floatValue, err := strconv.ParseFloat(raw, 64); err == nil {
+			return floatValue
+		}
+	}
+	switch strings.ToLower(raw) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return raw
+}
+
+/*
+Convert the value of the int or duration target with a unit, the bare numbers (e.g. "30") are in the unit
+and the durations (e.g. "1m") are converted into it, the ints must get a whole number of the unit
+*/
+func convertUnitValue(target reflect.Value, raw string, tagProp tagProperties) error {
+	if target.Type() == durationType {
+		if number, err := strconv.ParseFloat(raw, 64); err == nil {
+			// float64(math.MaxInt64) rounds up to 2^63, which is out of range itself
+			duration := number * float64(tagProp.Unit)
+			if math.IsNaN(duration) || duration < math.MinInt64 || duration >= math.MaxInt64 {
+				return &scalarError{"duration", fmt.Errorf("%s out of range", raw)}
+			}
+			target.SetInt(int64(duration))
+			return nil
+		}
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return &scalarError{"duration", err}
+		}
+		target.SetInt(int64(duration))
+		return nil
+	}
+	if intValue, err := strconv.ParseInt(raw, tagProp.Base, target.Type().Bits()); err == nil {
+		target.SetInt(intValue)
+		return nil
+	}
+	duration, err := time.ParseDuration(raw)
+	if err == nil && duration%tagProp.Unit != 0 {
+		err = fmt.Errorf("%s is not a whole number of %s", raw, tagProp.Unit)
+	}
+	if err == nil && target.OverflowInt(int64(duration/tagProp.Unit)) {
+		err = fmt.Errorf("%s out of range", raw)
+	}
+	if err != nil {
+		return &scalarError{"duration", err}
+	}
+	target.SetInt(int64(duration / tagProp.Unit))
+	return nil
+}
+
+// textUnmarshaler returns the text unmarshaler of the settable value if its type implements one
+func textUnmarshaler(target reflect.Value) (encoding.TextUnmarshaler, bool) {
+	if !target.CanAddr() {
+		return nil, false
+	}
+	unmarshaler, ok := target.Addr().Interface().(encoding.TextUnmarshaler)
+	return unmarshaler, ok
+}
+
+// isSQLNullType reports whether the type is a database/sql null type, e.g. sql.NullInt64 or sql.Null[T]
+func isSQLNullType(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && typ.PkgPath() == "database/sql" && strings.HasPrefix(typ.Name(), "Null") &&
+		typ.NumField() == 2 && typ.Field(1).Name == "Valid" && typ.Field(1).Type.Kind() == reflect.Bool
+}
+
+/*
+Set the inner value of the database/sql null type and mark it valid, the unset
+env vars without a default leave it invalid (e.g. sql.NullString{Valid: false})
+*/
+func setSQLNullValue(fieldValue reflect.Value, tagProp tagProperties, envValue string) error {
+	fieldValue.SetZero()
+	if tagProp.unset {
+		return nil
+	}
+	if err := convertScalar(fieldValue.Field(0), envValue, tagProp); err != nil {
+		return fieldScalarError(err, tagProp.EnvName, "unsupported field type", fieldValue.Field(0).Kind())
+	}
+	fieldValue.Field(1).SetBool(true)
+	return nil
+}
+
+// isScalarValue reports whether the value is converted as a single scalar and not split into a list or map
+func isScalarValue(target reflect.Value) bool {
+	if _, ok := textUnmarshaler(target); ok {
+		return true
+	}
+	switch target.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return false
+	}
+	return true
+}
+
+/*
+Describe the convertScalar error of a field or of a slice or array element with the env var name,
+the unsupported kinds are reported with the unsupported message of the caller
+*/
+func fieldScalarError(err error, envName string, unsupported string, kind reflect.Kind) error {
+	if errors.Is(err, errUnsupportedScalar) {
+		return &unsupportedTypeError{unsupported, kind}
+	}
+	var scalarErr *scalarError
+	if !errors.As(err, &scalarErr) {
+		return err
+	}
+	switch scalarErr.typeName {
+	case "text":
+		return fmt.Errorf("failed to unmarshal %s: %w", envName, scalarErr.err)
+	case "bool":
+		return fmt.Errorf("error parsing env var %s: %w", envName, scalarErr.err)
+	}
+	return fmt.Errorf("failed to convert %s to %s: %w", envName, scalarErr.typeName, scalarErr.err)
+}
+
+/*
+Describe the convertScalar error of a map key or value with the raw token, part is "key" or "value"
+*/
+func mapScalarError(err error, part string, raw string, kind reflect.Kind) error {
+	if errors.Is(err, errUnsupportedScalar) {
+		return &unsupportedTypeError{"unsupported map " + part + " type", kind}
+	}
+	var scalarErr *scalarError
+	if !errors.As(err, &scalarErr) {
+		return err
+	}
+	return fmt.Errorf("failed to convert map %s %s to %s: %w", part, raw, scalarErr.typeName, scalarErr.err)
+}
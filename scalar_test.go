@@ -0,0 +1,146 @@
+//go:build unit
+
+package envarfig
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertScalarParity loads the raw value into a T field, a []T slice and a map[string]T value
+func assertScalarParity[T any](t *testing.T, tag string, raw string, boolWords bool, expected T) {
+	t.Helper()
+	tagProp, err := parseTagAndTagValues(tag)
+	require.NoError(t, err)
+	tagProp.setDelimiter(";")
+	tagProp.setBoolWords(boolWords)
+
+	var scalar T
+	require.NoError(t, setEnvVarValues(reflect.ValueOf(&scalar).Elem(), tagProp, raw))
+	assert.Equal(t, expected, scalar)
+
+	var slice []T
+	require.NoError(t, setEnvVarValues(reflect.ValueOf(&slice).Elem(), tagProp, raw+";"+raw))
+	assert.Equal(t, []T{expected, expected}, slice)
+
+	var mapValues map[string]T
+	require.NoError(t, setEnvVarValues(reflect.ValueOf(&mapValues).Elem(), tagProp, "{key:"+raw+"}"))
+	assert.Equal(t, map[string]T{"key": expected}, mapValues)
+}
+
+func TestConvertScalarParity(t *testing.T) {
+	t.Run("base detection", func(t *testing.T) {
+		assertScalarParity(t, "FLAGS,base=0", "0x1F", false, 31)
+	})
+	t.Run("duration", func(t *testing.T) {
+		assertScalarParity(t, "TIMEOUT", "1m30s", false, 90*time.Second)
+	})
+	t.Run("unit", func(t *testing.T) {
+		assertScalarParity(t, "TIMEOUT,unit=seconds", "30", false, 30*time.Second)
+		assertScalarParity(t, "TTL,unit=ms", "2s", false, 2000)
+	})
+	t.Run("bytesize", func(t *testing.T) {
+		assertScalarParity(t, "LIMIT,bytesize", "10MB", false, uint64(10_000_000))
+	})
+	t.Run("percent", func(t *testing.T) {
+		assertScalarParity(t, "RATIO,percent", "75%", false, 0.75)
+	})
+	t.Run("bool words", func(t *testing.T) {
+		assertScalarParity(t, "ENABLED", "yes", true, true)
+	})
+	t.Run("scientific notation", func(t *testing.T) {
+		assertScalarParity(t, "THRESHOLD", "-1.5e10", false, -1.5e10)
+		assertScalarParity(t, "EPSILON", "2.5E-3", false, float32(2.5e-3))
+	})
+	t.Run("complex", func(t *testing.T) {
+		assertScalarParity(t, "POINT", "1 + 2i", false, complex(1, 2))
+	})
+	t.Run("time layout", func(t *testing.T) {
+		assertScalarParity(t, "SINCE,layout=2006-01-02", "2024-03-01", false, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	})
+	t.Run("text unmarshaler", func(t *testing.T) {
+		assertScalarParity(t, "ADDR", "10.0.0.1", false, netip.MustParseAddr("10.0.0.1"))
+	})
+}
+
+func TestConvertScalarErrors(t *testing.T) {
+	tagProp, err := parseTagAndTagValues("PORT")
+	require.NoError(t, err)
+
+	var port int8
+	err = setEnvVarValues(reflect.ValueOf(&port).Elem(), tagProp, "300")
+	assert.EqualError(t, err, `failed to convert PORT to int: strconv.ParseInt: parsing "300": value out of range`)
+
+	var ports []int8
+	err = setEnvVarValues(reflect.ValueOf(&ports).Elem(), tagProp, "80,300")
+	assert.EqualError(t, err, `failed to convert PORT to int: strconv.ParseInt: parsing "300": value out of range`)
+
+	var portMap map[string]int8
+	err = setEnvVarValues(reflect.ValueOf(&portMap).Elem(), tagProp, "{http:300}")
+	assert.EqualError(t, err, `failed to convert map value 300 to int: strconv.ParseInt: parsing "300": value out of range`)
+
+	// float32 values out of its range are reported instead of becoming infinite
+	var ratio float32
+	err = setEnvVarValues(reflect.ValueOf(&ratio).Elem(), tagProp, "1e39")
+	assert.EqualError(t, err, `failed to convert PORT to float: strconv.ParseFloat: parsing "1e39": value out of range`)
+
+	var ratios []float32
+	err = setEnvVarValues(reflect.ValueOf(&ratios).Elem(), tagProp, "1,-1e39")
+	assert.EqualError(t, err, `failed to convert PORT to float: strconv.ParseFloat: parsing "-1e39": value out of range`)
+
+	var ratioMap map[string]float32
+	err = setEnvVarValues(reflect.ValueOf(&ratioMap).Elem(), tagProp, "{max:1e39}")
+	assert.EqualError(t, err, `failed to convert map value 1e39 to float: strconv.ParseFloat: parsing "1e39": value out of range`)
+
+	// the bare numbers of a unit must be finite durations
+	unitProp, err := parseTagAndTagValues("TIMEOUT,unit=seconds")
+	require.NoError(t, err)
+	var timeout time.Duration
+	for _, raw := range []string{"1e300", "-1e300", "NaN", "Inf", "9223372037"} {
+		err = setEnvVarValues(reflect.ValueOf(&timeout).Elem(), unitProp, raw)
+		assert.EqualError(t, err, "failed to convert TIMEOUT to duration: "+raw+" out of range")
+	}
+	require.NoError(t, setEnvVarValues(reflect.ValueOf(&timeout).Elem(), unitProp, "9223372036"))
+	assert.Equal(t, 9223372036*time.Second, timeout)
+
+	// the percentages must be finite
+	percentProp, err := parseTagAndTagValues("RATIO,percent")
+	require.NoError(t, err)
+	for _, raw := range []string{"NaN", "Inf", "+Inf%", "-Inf"} {
+		err = setEnvVarValues(reflect.ValueOf(&ratio).Elem(), percentProp, raw)
+		assert.EqualError(t, err, "failed to convert RATIO to percent: "+raw+" out of range")
+	}
+	err = setEnvVarValues(reflect.ValueOf(&ratio).Elem(), percentProp, "1e41%")
+	assert.EqualError(t, err, "failed to convert RATIO to percent: 1e41% out of range")
+
+	var unsupported struct{}
+	assert.ErrorIs(t, convertScalar(reflect.ValueOf(&unsupported).Elem(), "x", tagProp), errUnsupportedScalar)
+}
+
+func TestInferScalar(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected any
+	}{
+		{"5", 5},
+		{"-12", -12},
+		{"2.5", 2.5},
+		{"1e3", 1e3},
+		{"true", true},
+		{"FALSE", false},
+		{"Inf", "Inf"},
+		{"NaN", "NaN"},
+		{"t", "t"},
+		{"0x1F", "0x1F"},
+		{"", ""},
+		{"hello", "hello"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, inferScalar(test.raw), test.raw)
+	}
+}
@@ -1,25 +1,144 @@
 package envarfig
 
+import (
+	"context"
+	"flag"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
 type settings struct {
-	AutoLoadEnv bool
-	CacheConfig bool
-	EnvFiles    []string
+	AutoLoadEnv             bool
+	CacheConfig             bool
+	EnvFiles                []string
+	LayeredFiles            []string
+	Environment             string
+	EnvironmentVar          string
+	Lenient                 bool
+	LenientBool             bool
+	DefaultsOnly            bool
+	Defaults                map[string]string
+	Precedence              []ValueSource
+	Transforms              map[string]func(value string) string
+	FileFallback            bool
+	AllowEmptyDefault       bool
+	MaxEnvFileSize          int64
+	ExclusiveGroups         bool
+	EnvFileOptional         bool
+	OverloadEnv             bool
+	EnvReader               *envReaderContent
+	NameStrategy            func(fieldName string) string
+	TagName                 string
+	UnusedPrefix            string
+	EnvSource               EnvSource
+	DeprecationLogger       func(msg string)
+	Logger                  *slog.Logger
+	OnMissing               func(envName string, usedDefault bool)
+	ErrorOnUnknownRef       bool
+	ExpandDefaults          bool
+	ExpandVars              bool
+	ErrorOnDuplicateMapKeys bool
+	JSONMapFallback         bool
+	AllowShortArray         bool
+	TruncateArray           bool
+	IgnoreTrailingDelimiter bool
+	EmptyAsNil              bool
+	TrimQuotes              bool
+	EnvSnapshot             bool
+	FieldNamesInErrors      bool
+	RequiredAll             bool
+	ErrorFormatter          func(field string, envName string, err error) error
+	FlagSet                 *flag.FlagSet
+	ctx                     context.Context
+	envContent              map[string]string
+	envSources              []envSource
+	envGroups               map[string]*envGroup
+	requiredIfs             []requiredIfCheck
+	fieldValues             map[string]string
+	fieldSources            map[string]string
+	fieldFilter             map[string]bool
+	readEnvFiles            bool
+	joinFieldErrors         bool
+	flagFields              map[string]flagField
+	envPrefix               string
 }
 
 type option func(*settings)
 
+var (
+	defaultOptions   []option     // options applied to every load before its own options
+	defaultOptionsMu sync.RWMutex // guards the default options
+	defaultNoCache   atomic.Bool  // disables the config caching by default
+)
+
+/*
+info: sets whether the configs are cached by default (e.g. disabled for a whole test suite),
+the default options and WithCacheConfig of each call still override it
+
+useage: SetDefaultCache(false)
+
+args:
+  - cacheConfig: whether the configs are cached by default
+*/
+func SetDefaultCache(cacheConfig bool) {
+	defaultNoCache.Store(!cacheConfig)
+}
+
+/*
+info: sets the options applied to every load (e.g. the env source), the options of
+each call still override them, calling it again replaces the previous default options
+
+useage: SetDefaultOptions(WithEnvSource(source), WithLenient(true))
+
+args:
+  - opts: the default options, none to clear them
+*/
+func SetDefaultOptions(opts ...option) {
+	defaultOptionsMu.Lock()
+	defer defaultOptionsMu.Unlock()
+	defaultOptions = append([]option(nil), opts...)
+}
+
 func loadSettings(opts ...option) *settings {
 	setting := &settings{
-		AutoLoadEnv: true,
-		EnvFiles:    nil,
-		CacheConfig: true,
+		AutoLoadEnv:  true,
+		EnvFiles:     nil,
+		CacheConfig:  !defaultNoCache.Load(),
+		NameStrategy: toScreamingSnakeCase,
+		TagName:      defaultTagName,
+		EnvSource:    osEnvSource{},
+		ctx:          context.Background(),
+	}
+	defaultOptionsMu.RLock()
+	for _, opt := range defaultOptions {
+		opt(setting)
 	}
+	defaultOptionsMu.RUnlock()
 	for _, opt := range opts {
 		opt(setting)
 	}
 	return setting
 }
 
+// resolveEnvironment picks the active environment from the environment var, looked up
+// through the env source, when no environment was set explicitly
+func (s *settings) resolveEnvironment() error {
+	if s.Environment != "" || s.EnvironmentVar == "" {
+		return nil
+	}
+	environment, _, ok, err := s.lookupEnvSource(s.EnvironmentVar)
+	if err != nil {
+		return err
+	}
+	if ok {
+		s.Environment = strings.TrimSpace(environment)
+	}
+	return nil
+}
+
 // WithEnvFiles sets the env file paths
 func WithEnvFiles(envFiles ...string) option {
 	return func(s *settings) {
@@ -27,6 +146,14 @@ func WithEnvFiles(envFiles ...string) option {
 	}
 }
 
+// WithLayeredFiles sets the layered env file paths, read in order with the later files
+// overriding the earlier ones and the env vars overriding all of them, instead of loading the env files
+func WithLayeredFiles(layeredFiles ...string) option {
+	return func(s *settings) {
+		s.LayeredFiles = layeredFiles
+	}
+}
+
 // WithAutoLoadEnv sets the use env file option
 func WithAutoLoadEnv(AutoLoadEnv bool) option {
 	return func(s *settings) {
@@ -40,3 +167,317 @@ func WithCacheConfig(CacheConfig bool) option {
 		s.CacheConfig = CacheConfig
 	}
 }
+
+// WithEnvironment sets the active environment (e.g. "staging"), used to
+// select environment specific env files and defaults
+func WithEnvironment(environment string) option {
+	return func(s *settings) {
+		s.Environment = environment
+	}
+}
+
+// WithEnvironmentVar sets the env var (e.g. "APP_ENV") the active environment is read from through
+// the env source when WithEnvironment is not set
+func WithEnvironmentVar(environmentVar string) option {
+	return func(s *settings) {
+		s.EnvironmentVar = environmentVar
+	}
+}
+
+// WithLenient sets the lenient option, which coerces common mismatches:
+//   - surrounding whitespace and matching quotes are trimmed from every value
+//   - on/off, yes/no and y/n are accepted for bool fields, slice elements and map keys and values
+//   - empty values are treated as zero for numeric fields
+func WithLenient(lenient bool) option {
+	return func(s *settings) {
+		s.Lenient = lenient
+	}
+}
+
+// WithLenientBool parses any integer as a bool when the standard parsing fails,
+// nonzero is true and 0 is false (e.g. BOOL=2 or BOOL=-1 of legacy integrations)
+func WithLenientBool(lenientBool bool) option {
+	return func(s *settings) {
+		s.LenientBool = lenientBool
+	}
+}
+
+// WithEnvFileOptional sets the env file optional option, missing env files
+// are ignored while other load errors are still returned
+func WithEnvFileOptional(envFileOptional bool) option {
+	return func(s *settings) {
+		s.EnvFileOptional = envFileOptional
+	}
+}
+
+// WithMaxEnvFileSize sets the max size in bytes of each env file and of the env content of
+// WithEnvReader, the larger ones are rejected before they are parsed (default is 0, unlimited)
+func WithMaxEnvFileSize(maxEnvFileSize int64) option {
+	return func(s *settings) {
+		s.MaxEnvFileSize = maxEnvFileSize
+	}
+}
+
+// WithOverloadEnv sets the overload env option, the env file values override
+// the already set env vars (uses godotenv.Overload)
+func WithOverloadEnv(overloadEnv bool) option {
+	return func(s *settings) {
+		s.OverloadEnv = overloadEnv
+	}
+}
+
+// WithEnvReader sets the reader of the dotenv content, which is parsed instead of loading
+// the env files, the content is read once and kept so the option can be reused
+func WithEnvReader(envReader io.Reader) option {
+	content := &envReaderContent{reader: envReader}
+	return func(s *settings) {
+		s.EnvReader = content
+	}
+}
+
+// WithEnvContent sets the dotenv content, which is parsed instead of
+// loading the env files
+func WithEnvContent(envContent string) option {
+	content := &envReaderContent{content: []byte(envContent), done: true}
+	return func(s *settings) {
+		s.EnvReader = content
+	}
+}
+
+// WithNameStrategy sets the strategy deriving the env var name from the field
+// name, used when the env tag has no name (default is SCREAMING_SNAKE_CASE)
+func WithNameStrategy(nameStrategy func(fieldName string) string) option {
+	return func(s *settings) {
+		s.NameStrategy = nameStrategy
+	}
+}
+
+// WithErrorOnUnused sets the prefix of the env vars that must all be used,
+// loading fails when an env var with the prefix is not used by any field
+func WithErrorOnUnused(prefix string) option {
+	return func(s *settings) {
+		s.UnusedPrefix = prefix
+	}
+}
+
+// WithEnvSource sets the source the env vars are looked up in (e.g. a remote
+// secret backend), the process env is used by default
+func WithEnvSource(envSource EnvSource) option {
+	return func(s *settings) {
+		s.EnvSource = envSource
+	}
+}
+
+// WithExpandDefaults sets the expand defaults option, the ${VAR} and $VAR references of the
+// default values are expanded against the env source ("$$" is a literal "$"), the defaults
+// are kept as is without it
+func WithExpandDefaults(expandDefaults bool) option {
+	return func(s *settings) {
+		s.ExpandDefaults = expandDefaults
+	}
+}
+
+// WithErrorOnUnknownRef sets the unknown reference option, loading fails when an expanded
+// value references an unset env var instead of expanding it to empty
+func WithErrorOnUnknownRef(errorOnUnknownRef bool) option {
+	return func(s *settings) {
+		s.ErrorOnUnknownRef = errorOnUnknownRef
+	}
+}
+
+// WithExpandVars sets the expand vars option, the ${VAR} and $VAR references of the
+// string values and slice elements are expanded against the env source ("$$" is a literal "$")
+func WithExpandVars(expandVars bool) option {
+	return func(s *settings) {
+		s.ExpandVars = expandVars
+	}
+}
+
+// WithErrorOnDuplicateMapKeys sets the duplicate map keys option, loading fails when
+// a map env var has the same key more than once instead of keeping the last value
+func WithErrorOnDuplicateMapKeys(errorOnDuplicateMapKeys bool) option {
+	return func(s *settings) {
+		s.ErrorOnDuplicateMapKeys = errorOnDuplicateMapKeys
+	}
+}
+
+// WithDefaultsOnly sets the defaults only option, the fields are loaded from their default
+// values ignoring the env files and the env vars (e.g. for deterministic test fixtures)
+func WithDefaultsOnly(defaultsOnly bool) option {
+	return func(s *settings) {
+		s.DefaultsOnly = defaultsOnly
+	}
+}
+
+// WithDeprecationLogger sets the logger warned when a value is set under a deprecated
+// env var name (e.g. `env:"NEW,deprecated=OLD"`), helping to migrate the env var names
+func WithDeprecationLogger(deprecationLogger func(msg string)) option {
+	return func(s *settings) {
+		s.DeprecationLogger = deprecationLogger
+	}
+}
+
+// WithLogger sets the logger of the load diagnostics, the resolved fields are logged at the
+// debug level with their env var and source, the values of the secret fields are redacted
+func WithLogger(logger *slog.Logger) option {
+	return func(s *settings) {
+		s.Logger = logger
+	}
+}
+
+// WithAllowEmptyDefault sets the allow empty default option, an empty tag default (e.g.
+// default= or default="") is used as a real empty value which satisfies required
+func WithAllowEmptyDefault(allowEmptyDefault bool) option {
+	return func(s *settings) {
+		s.AllowEmptyDefault = allowEmptyDefault
+	}
+}
+
+// WithDefaults sets the default values by env var name, used for the fields without a
+// default in their tag so the defaults can be kept in code and shared across configs
+func WithDefaults(defaults map[string]string) option {
+	return func(s *settings) {
+		s.Defaults = defaults
+	}
+}
+
+// WithPrecedence sets the order of the value sources, the first source with a value wins and
+// the sources left out are not used, e.g. WithPrecedence(SourceEnvFiles, SourceTagDefault)
+// ignores the process env (default is the process env, env files, tag default and defaults map)
+func WithPrecedence(sources ...ValueSource) option {
+	return func(s *settings) {
+		s.Precedence = sources
+	}
+}
+
+// WithTransform sets a transform of the raw value of the field run before the conversion (e.g.
+// strings.ToLower or filepath.Clean), the field is named "Field" or "Struct.Field" for a single struct
+func WithTransform(fieldPath string, transform func(value string) string) option {
+	return func(s *settings) {
+		if s.Transforms == nil {
+			s.Transforms = make(map[string]func(value string) string)
+		}
+		s.Transforms[fieldPath] = transform
+	}
+}
+
+// WithFileFallback sets the file fallback option, an unset env var X is read from the file
+// named by X_FILE when it is set (the Docker and Kubernetes secrets convention)
+func WithFileFallback(fileFallback bool) option {
+	return func(s *settings) {
+		s.FileFallback = fileFallback
+	}
+}
+
+// WithTagName sets the struct tag key the env tags are read from (default is "env"),
+// avoiding tag collisions when a struct is shared with other config loaders
+func WithTagName(tagName string) option {
+	return func(s *settings) {
+		s.TagName = tagName
+	}
+}
+
+// WithExclusiveGroups sets the exclusive groups option, exactly one env var of each
+// group (e.g. `env:"API_KEY,group=auth"`) must be set instead of at least one
+func WithExclusiveGroups(exclusiveGroups bool) option {
+	return func(s *settings) {
+		s.ExclusiveGroups = exclusiveGroups
+	}
+}
+
+// WithOnMissing sets the callback invoked for each env var that is not set, with whether
+// the default value is used instead (e.g. to count the reliance on defaults in production)
+func WithOnMissing(onMissing func(envName string, usedDefault bool)) option {
+	return func(s *settings) {
+		s.OnMissing = onMissing
+	}
+}
+
+// WithJSONMapFallback sets the json map fallback option, a map env var value failing the
+// {key:value} parse is parsed as a json object (e.g. {"a":"1,2"}) when it looks like one
+func WithJSONMapFallback(jsonMapFallback bool) option {
+	return func(s *settings) {
+		s.JSONMapFallback = jsonMapFallback
+	}
+}
+
+// WithAllowShortArray sets the allow short array option, the missing trailing elements of
+// fixed size arrays are zero-filled instead of failing (too many values still fail)
+func WithAllowShortArray(allowShortArray bool) option {
+	return func(s *settings) {
+		s.AllowShortArray = allowShortArray
+	}
+}
+
+// WithTruncateArray sets the truncate array option, the extra values of fixed size arrays
+// are dropped instead of failing, combined with WithAllowShortArray any number of values is accepted
+func WithTruncateArray(truncateArray bool) option {
+	return func(s *settings) {
+		s.TruncateArray = truncateArray
+	}
+}
+
+// WithIgnoreTrailingDelimiter sets the ignore trailing delimiter option, the empty last element left
+// by a trailing delimiter of a slice, array or map (e.g. "a,b,c," or "{a:1,b:2,}") is dropped
+func WithIgnoreTrailingDelimiter(ignoreTrailingDelimiter bool) option {
+	return func(s *settings) {
+		s.IgnoreTrailingDelimiter = ignoreTrailingDelimiter
+	}
+}
+
+// WithEmptyAsNil sets the empty as nil option, empty values leave the slice and map
+// fields nil instead of an empty container or a slice with one empty element
+func WithEmptyAsNil(emptyAsNil bool) option {
+	return func(s *settings) {
+		s.EmptyAsNil = emptyAsNil
+	}
+}
+
+// WithTrimQuotes sets the trim quotes option, which strips the matching " or ' quotes
+// wrapping scalar values (e.g. HOST="localhost" read straight from the environment)
+func WithTrimQuotes(trimQuotes bool) option {
+	return func(s *settings) {
+		s.TrimQuotes = trimQuotes
+	}
+}
+
+// WithEnvSnapshot captures the process env once the env files are loaded and looks up
+// the env vars in the snapshot for the whole parse, instead of the env source
+func WithEnvSnapshot() option {
+	return func(s *settings) {
+		s.EnvSnapshot = true
+	}
+}
+
+// WithFieldNamesInErrors prefixes the field errors with the struct field name, so they are
+// easier to locate in large structs, e.g. "field Config.Port (PORT): failed to convert PORT to int: ..."
+func WithFieldNamesInErrors(fieldNamesInErrors bool) option {
+	return func(s *settings) {
+		s.FieldNamesInErrors = fieldNamesInErrors
+	}
+}
+
+// WithErrorFormatter sets the formatter of the field load errors, called with the struct field
+// name (e.g. "Config.Port"), the env var name and the error to return its own error instead
+func WithErrorFormatter(errorFormatter func(field string, envName string, err error) error) option {
+	return func(s *settings) {
+		s.ErrorFormatter = errorFormatter
+	}
+}
+
+// WithRequiredAll makes every field required, except the fields with a default (even an
+// empty default=) and the fields opting out with required=false
+func WithRequiredAll(requiredAll bool) option {
+	return func(s *settings) {
+		s.RequiredAll = requiredAll
+	}
+}
+
+// WithFlagSet sets the command-line flags overriding the env vars, a flag explicitly set on the
+// command line replaces the value of its field, the flag name is the lowercased env var name with
+// dashes (e.g. -db-host for DB_HOST), the configs loaded with flags are not cached
+func WithFlagSet(flagSet *flag.FlagSet) option {
+	return func(s *settings) {
+		s.FlagSet = flagSet
+	}
+}
@@ -56,3 +56,34 @@ func TestSettings(t *testing.T) {
 		}
 	})
 }
+
+func TestSetDefaultOptions(t *testing.T) {
+	t.Cleanup(func() { SetDefaultOptions() })
+
+	SetDefaultOptions(WithLenient(true), WithEnvFiles("default.env"))
+	s := loadSettings(WithEnvFiles("call.env"))
+	assert.True(t, s.Lenient)
+	assert.Equal(t, []string{"call.env"}, s.EnvFiles)
+
+	// the default options are replaced, not merged with the previous ones
+	SetDefaultOptions(WithCacheConfig(false))
+	s = loadSettings()
+	assert.False(t, s.Lenient)
+	assert.False(t, s.CacheConfig)
+
+	SetDefaultOptions()
+	s = loadSettings()
+	assert.True(t, s.CacheConfig)
+}
+
+func TestSetDefaultCache(t *testing.T) {
+	t.Cleanup(func() { SetDefaultCache(true) })
+
+	SetDefaultCache(false)
+	assert.False(t, loadSettings().CacheConfig)
+	// the options of each call override the default
+	assert.True(t, loadSettings(WithCacheConfig(true)).CacheConfig)
+
+	SetDefaultCache(true)
+	assert.True(t, loadSettings().CacheConfig)
+}
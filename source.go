@@ -0,0 +1,72 @@
+package envarfig
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// EnvSource is a source of the env vars (e.g. a remote secret backend), the lookups
+// should respect the cancellation of the context
+type EnvSource interface {
+	// Lookup returns the value of the env var and whether it is set
+	Lookup(ctx context.Context, key string) (string, bool, error)
+}
+
+// EnvKeyLister is implemented by the env sources listing the names of their env vars, which the
+// glob maps, the indexed struct slices, the versioned env vars and WithErrorOnUnused match against,
+// loading these with an env source not implementing it fails with ErrEnvKeysUnsupported
+type EnvKeyLister interface {
+	// Keys returns the names of the env vars of the source
+	Keys(ctx context.Context) ([]string, error)
+}
+
+// osEnvSource is the default source, looking up the env vars in the process env
+type osEnvSource struct{}
+
+// Lookup looks up the env var in the process env, the context is ignored
+func (osEnvSource) Lookup(_ context.Context, key string) (string, bool, error) {
+	value, ok := os.LookupEnv(key)
+	return value, ok, nil
+}
+
+// Keys returns the names of the env vars in the process env, the context is ignored
+func (osEnvSource) Keys(_ context.Context) ([]string, error) {
+	environ := os.Environ()
+	names := make([]string, 0, len(environ))
+	for _, env := range environ {
+		name, _, _ := strings.Cut(env, "=")
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// snapshotEnvSource looks up the env vars in a snapshot of the process env, so the
+// changes of other goroutines during the parse are not seen
+type snapshotEnvSource map[string]string
+
+// newSnapshotEnvSource captures the current process env
+func newSnapshotEnvSource() snapshotEnvSource {
+	environ := os.Environ()
+	snapshot := make(snapshotEnvSource, len(environ))
+	for _, env := range environ {
+		name, value, _ := strings.Cut(env, "=")
+		snapshot[name] = value
+	}
+	return snapshot
+}
+
+// Lookup looks up the env var in the snapshot, the context is ignored
+func (s snapshotEnvSource) Lookup(_ context.Context, key string) (string, bool, error) {
+	value, ok := s[key]
+	return value, ok, nil
+}
+
+// Keys returns the names of the env vars in the snapshot, the context is ignored
+func (s snapshotEnvSource) Keys(_ context.Context) ([]string, error) {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	return names, nil
+}